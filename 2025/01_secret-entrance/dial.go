@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"aoclib/intmath"
+)
+
+// Event is what a single rotation did to the dial.
+type Event struct {
+	Dir       byte // 'R' or 'L'
+	Steps     int
+	From, To  int  // position before and after
+	Stop      bool // the rotation ended on 0
+	Crossings int  // clicks that pointed at 0 during the rotation, the last click included
+}
+
+// Dial is a safe dial with positions 0..size-1 that counts both passwords as it turns:
+// how often a rotation stops at 0 (step 1) and how often any click points at 0 (step 2).
+type Dial struct {
+	size      int
+	pos       int
+	stops     int
+	crossings int
+
+	OnRotate func(Event) // called after every rotation if set, for tracing
+}
+
+// NewDial returns a dial of size positions pointing at start.
+func NewDial(size, start int) (*Dial, error) {
+	if size < 1 || size > math.MaxInt/2 { // pos+rest below must not overflow
+		return nil, fmt.Errorf("dial size must be between 1 and %d, got %d", math.MaxInt/2, size)
+	}
+	if start < 0 || start >= size {
+		return nil, fmt.Errorf("start must be between 0 and %d, got %d", size-1, start)
+	}
+	return &Dial{size: size, pos: start}, nil
+}
+
+// Rotate turns the dial steps clicks to the right (towards higher numbers) or left.
+func (d *Dial) Rotate(dir byte, steps int) (Event, error) {
+	if steps < 0 {
+		return Event{}, fmt.Errorf("steps must not be negative, got %d", steps)
+	}
+	e := Event{Dir: dir, Steps: steps, From: d.pos}
+
+	// full turns pass 0 once each, only the remaining steps depend on the position,
+	// splitting them off also keeps pos+steps from overflowing on huge steps
+	turns, rest := steps/d.size, steps%d.size
+	switch dir {
+	case 'R':
+		// how many multiples of size are in the range (pos, pos+steps]
+		e.Crossings = turns + (d.pos+rest)/d.size
+		d.pos = intmath.Mod(d.pos+rest, d.size)
+	case 'L':
+		// how many multiples of size are in the range [pos-steps, pos)
+		// tricky: go division of negative number truncates, e.g. -2/100=0 but we need -1
+		e.Crossings = turns + intmath.FloorDiv(d.pos-1, d.size) - intmath.FloorDiv(d.pos-1-rest, d.size)
+		d.pos = intmath.Mod(d.pos-rest, d.size)
+	default:
+		return Event{}, fmt.Errorf("invalid direction %q, expected R or L", dir)
+	}
+	e.To = d.pos
+	e.Stop = d.pos == 0
+
+	if e.Stop {
+		d.stops++
+	}
+	d.crossings += e.Crossings
+	if d.OnRotate != nil {
+		d.OnRotate(e)
+	}
+	return e, nil
+}
+
+// Position returns where the dial points now.
+func (d *Dial) Position() int {
+	return d.pos
+}
+
+// Stops returns how many rotations ended on 0, the step 1 password.
+func (d *Dial) Stops() int {
+	return d.stops
+}
+
+// Crossings returns how many clicks pointed at 0, the step 2 password.
+func (d *Dial) Crossings() int {
+	return d.crossings
+}
@@ -1,17 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"strconv"
+
+	"aoclib/input"
 )
 
 func main() {
-	s2 := flag.Bool("s2", false, "enable step 2 password logic")
+	size := flag.Int("size", 100, "number of positions on the dial")
+	start := flag.Int("start", 50, "position the dial points at before the first rotation")
+	trace := flag.Bool("trace", false, "print every rotation")
 	flag.Parse() // important!
 
 	args := flag.Args() // get positional arguments
@@ -22,64 +24,52 @@ func main() {
 		os.Exit(1)
 	}
 
-	pass, err := getPassword(args[0], *s2)
+	dial, err := NewDial(*size, *start)
 	if err != nil {
 		log.Fatalf("error: %s", err)
 	}
-	fmt.Printf("The password is: %d\n", pass)
+	if *trace {
+		dial.OnRotate = func(e Event) {
+			fmt.Printf("%c%d: %d -> %d, zero crossings %d, stop at zero %v\n",
+				e.Dir, e.Steps, e.From, e.To, e.Crossings, e.Stop)
+		}
+	}
+
+	if err := getPassword(args[0], dial); err != nil {
+		log.Fatalf("error: %s", err)
+	}
+	fmt.Printf("The step 1 password is: %d\n", dial.Stops())
+	fmt.Printf("The step 2 password is: %d\n", dial.Crossings())
 }
 
-func getPassword(fname string, s2 bool) (int, error) {
-	// open file
-	file, err := os.Open(fname)
+// getPassword turns the dial through every rotation of the file, both passwords in one pass
+func getPassword(fname string, dial *Dial) error {
+	// read whole file, lines keep their number for error messages
+	lines, err := input.Lines(fname)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %w", err)
+		return fmt.Errorf("failed to read file: %w", err)
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			log.Printf("failed to close file: %s", err)
-		}
-	}()
 
-	dialPos := 50
-	zeroCount := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() { // read line by line
-		line := scanner.Text()
+	for _, at := range lines { // process line by line
+		line := at.Text
 		if len(line) < 2 {
-			return 0, fmt.Errorf("invalid line: %s", line)
+			return at.Errorf(len(line)+1, "expected direction and steps, e.g. R42")
 		} // prevent panic on slicing
 
+		if line[0] != 'R' && line[0] != 'L' {
+			return at.Errorf(1, "invalid direction %q, expected R or L", line[0])
+		}
 		numSteps, err := strconv.Atoi(line[1:]) // string to int
 		if err != nil {
-			return 0, fmt.Errorf("failed to convert steps to integer: %w", err)
+			return at.Errorf(2, "invalid steps %q: %w", line[1:], err)
 		}
-
-		switch line[0] {
-		case 'R':
-			if s2 {
-				// we want to know how many times `(s+1)%100, (s+2)%100, ..., (s+n)%100` equal to 0
-				// equivalent to how many multiples of 100 are in the range (dialPos, dialPos+numSteps]
-				zeroCount += (dialPos + numSteps) / 100
-			}
-			dialPos = (dialPos + numSteps) % 100 // simple modulo logic to wrap around
-		case 'L':
-			if s2 {
-				// how many multiples of 100 are in the range [dialPos-numSteps, dialPos)
-				// tricky: go floor division of negative number is unlike math, e.g. -2/100=0!=-1
-				right := math.Floor(float64(dialPos-1) / 100)
-				left := math.Floor(float64(dialPos-1-numSteps) / 100)
-				zeroCount += int(right - left)
-			}
-			dialPos = ((dialPos-numSteps)%100 + 100) % 100 // tricky: go mod of negative number
-		default:
-			return 0, fmt.Errorf("invalid direction: %q", line[0])
+		if numSteps < 0 {
+			return at.Errorf(2, "steps must not be negative, got %d", numSteps)
 		}
 
-		if !s2 && dialPos == 0 {
-			zeroCount++
-		} // password is number of times dial stop at 0
+		if _, err := dial.Rotate(line[0], numSteps); err != nil {
+			return at.Locate(err)
+		}
 	}
-
-	return zeroCount, nil
+	return nil
 }
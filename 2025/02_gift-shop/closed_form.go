@@ -0,0 +1,132 @@
+package main
+
+import (
+	"aoclib/checked"
+	"aoclib/intmath"
+)
+
+// instead of checking every id, generate the invalid ones directly:
+// a D-digit id made of a pattern P of length L repeated k = D/L times is P * R,
+// where R = 1 + b^L + b^2L + ... + b^(k-1)L in base b, e.g. 123123 = 123 * 1001
+// so for a fixed D and L the invalid ids in [lo, hi] are R*P for P in a contiguous range,
+// and their sum is R times an arithmetic series: the work depends on digits, not range width
+
+// tally is how many invalid ids a range holds and what they add up to
+type tally struct {
+	count int
+	sum   checked.Int
+}
+
+func (t tally) add(o tally) tally { return tally{t.count + o.count, t.sum.Add(o.sum)} }
+func (t tally) sub(o tally) tally { return tally{t.count - o.count, t.sum.Sub(o.sum)} }
+
+// sumClosedForm sums the invalid ids of all ranges, same rules as rules.match
+func sumClosedForm(ranges [][2]int, r rules) checked.Int {
+	var total checked.Int
+	for _, rng := range ranges {
+		total = total.Add(tallyRange(rng, r).sum)
+	}
+	return total
+}
+
+// tallyRange counts and sums the invalid ids of a single range
+func tallyRange(rng [2]int, r rules) tally {
+	var total tally
+	forEachDigits(rng, r, func(digits, lo, hi int) {
+		total = total.add(tallyDigits(digits, lo, hi, r))
+	})
+	return total
+}
+
+// forEachDigits splits rng into the parts [lo, hi] whose ids all have the same number of digits
+func forEachDigits(rng [2]int, r rules, fn func(digits, lo, hi int)) {
+	for digits := r.numDigits(rng[0]); digits <= r.numDigits(rng[1]); digits++ {
+		lo := max(rng[0], intmath.Pow(r.base, uint(digits-1)))
+		hi := rng[1]
+		if digits < r.maxDigits() {
+			hi = min(hi, intmath.Pow(r.base, uint(digits))-1)
+		}
+		fn(digits, lo, hi)
+	}
+}
+
+// tallyDigits counts and sums the digits-long ids in [lo, hi] that the rules call invalid.
+//
+// ids can repeat several patterns: 111111 is 1 repeated 6 times, 11 repeated 3 times and 111 twice,
+// so summing per pattern length counts it thrice. instead, sort ids by how often their shortest
+// pattern repeats: one that repeats m times also repeats every k dividing m (fewer, longer copies),
+// so tallyPattern for k repeats is the sum over those m. going from the most repeats down and
+// taking off the larger m leaves the ids that repeat exactly k times, and those are invalid
+// when the rules allow any repeat count dividing k
+func tallyDigits(digits, lo, hi int, r rules) tally {
+	var counts []int // possible repeat counts, most first
+	for k := digits; k >= 2; k-- {
+		if digits%k == 0 {
+			counts = append(counts, k)
+		}
+	}
+
+	exact := make(map[int]tally, len(counts))
+	var total tally
+	for _, k := range counts {
+		t := tallyPattern(digits, digits/k, lo, hi, r.base)
+		for _, m := range counts {
+			if m > k && m%k == 0 {
+				t = t.sub(exact[m])
+			}
+		}
+		exact[k] = t
+		for j := 2; j <= k; j++ {
+			if k%j == 0 && r.allows(j) {
+				total = total.add(t)
+				break
+			}
+		}
+	}
+	return total
+}
+
+// tallyPattern counts and sums the digits-long ids in [lo, hi] that repeat a pattern of patternLen digits
+func tallyPattern(digits, patternLen, lo, hi, base int) tally {
+	repeat, pLo, pHi := patternRange(digits, patternLen, lo, hi, base)
+	if pLo > pHi {
+		return tally{}
+	}
+
+	// R * (pLo + ... + pHi), halving whichever factor of the series is even
+	count, ends := pHi-pLo+1, pLo+pHi
+	half := count
+	if count%2 == 0 {
+		half /= 2
+	} else {
+		ends /= 2
+	}
+	return tally{count, checked.NewInt(repeat).Mul(checked.NewInt(half)).Mul(checked.NewInt(ends))}
+}
+
+// patternRange returns the repeat factor R and the patterns pLo..pHi (empty if pLo > pHi)
+// for which R*P is a digits-long id in [lo, hi]
+func patternRange(digits, patternLen, lo, hi, base int) (repeat, pLo, pHi int) {
+	// R = 1 + b^L + ... + b^(k-1)L, in base 10 never above 1.2e18 but other bases
+	// can overflow on the longest ids, then R*P is past any id and there are no patterns
+	step := intmath.Pow(base, uint(patternLen))
+	for range digits / patternLen {
+		next, ok := checked.Mul(repeat, step)
+		if ok {
+			next, ok = checked.Add(next, 1)
+		}
+		if !ok {
+			return 1, 1, 0
+		}
+		repeat = next
+	}
+
+	// patterns have no leading zero, and R*P must land inside [lo, hi]
+	pLo = lo / repeat
+	if lo%repeat != 0 {
+		pLo++ // ceil, without lo+repeat-1 which could overflow
+	}
+	pLo = max(pLo, intmath.Pow(base, uint(patternLen-1)))
+	pHi = min(step-1, hi/repeat)
+	return repeat, pLo, pHi
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"aoclib/checked"
+	"aoclib/intmath"
+)
+
+// bruteTally checks every id of rng with rules.match, the reference for tallyRange
+func bruteTally(rng [2]int, r rules) tally {
+	var t tally
+	for id := rng[0]; id <= rng[1] && id >= rng[0]; id++ { // id >= rng[0] stops at math.MaxInt
+		if _, _, ok := r.match(id); ok {
+			t = t.add(tally{1, checked.NewInt(id)})
+		}
+	}
+	return t
+}
+
+// randomRules picks a base and a repeat rule, the puzzle's two included
+func randomRules(rng *rand.Rand) rules {
+	r := rules{base: []int{2, 3, 7, 10, 16, 36}[rng.Intn(6)], minRepeats: 2 + rng.Intn(3)}
+	if rng.Intn(2) == 0 {
+		r.maxRepeats = r.minRepeats + rng.Intn(3)
+	}
+	return r
+}
+
+// randomRange returns a range of at most a few thousand ids: anywhere, across a digit count
+// change (base^d), or up to math.MaxInt
+func randomRange(rng *rand.Rand, r rules) [2]int {
+	width := rng.Intn(3000)
+	var lo int
+	switch rng.Intn(3) {
+	case 0:
+		lo = rng.Intn(1 << (1 + rng.Intn(62)))
+	case 1:
+		lo = max(intmath.Pow(r.base, uint(1+rng.Intn(r.maxDigits()-1)))-rng.Intn(width+1), 0)
+	default:
+		return [2]int{math.MaxInt - width, math.MaxInt}
+	}
+	return [2]int{lo, lo + min(width, math.MaxInt-lo)}
+}
+
+func TestTallyRangeMatchesBrute(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	withIDs := 0
+	for range 3000 {
+		r := randomRules(rng)
+		span := randomRange(rng, r)
+		got, want := tallyRange(span, r), bruteTally(span, r)
+		if got.count != want.count || got.sum.Cmp(want.sum) != 0 {
+			t.Fatalf("tallyRange(%v, %+v) = %d ids summing to %d, brute force found %d summing to %d",
+				span, r, got.count, got.sum, want.count, want.sum)
+		}
+		if want.count > 0 {
+			withIDs++
+		}
+	}
+	// agreeing on empty ranges proves little, make sure enough of them held invalid ids
+	if withIDs < 300 {
+		t.Fatalf("only %d of 3000 random ranges held invalid ids", withIDs)
+	}
+}
+
+func TestSumClosedFormMatchesBrute(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for range 200 {
+		r := randomRules(rng)
+		ranges := make([][2]int, 1+rng.Intn(5))
+		for i := range ranges {
+			ranges[i] = randomRange(rng, r)
+		}
+		ranges = mergeRanges(ranges) // overlapping ranges would count an id twice in both
+		want, err := sumBrute(context.Background(), ranges, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sumClosedForm(ranges, r); got.Cmp(want) != 0 {
+			t.Fatalf("sumClosedForm(%v, %+v) = %d, brute force got %d", ranges, r, got, want)
+		}
+	}
+}
+
+func TestSumClosedFormExample(t *testing.T) {
+	ranges, err := readRanges("test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		r    rules
+		want int
+	}{
+		{mirrored, 1227775554},
+		{repeated, 4174379265},
+	} {
+		t.Run(fmt.Sprintf("%+v", tt.r), func(t *testing.T) {
+			if got := sumClosedForm(mergeRanges(ranges), tt.r); got.Cmp(checked.NewInt(tt.want)) != 0 {
+				t.Errorf("sumClosedForm(test1) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
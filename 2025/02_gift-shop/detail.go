@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"aoclib/checked"
+)
+
+// the detail report shows what makes up the total, to check it against the puzzle's worked example:
+// per range the invalid ids (only the first few of huge ranges) and the pattern each one repeats
+
+// invalidID is an invalid id and the shortest pattern it repeats, 1212 is 12 ×2
+type invalidID struct {
+	ID      int    `json:"id"`
+	Pattern string `json:"pattern"`
+	Repeats int    `json:"repeats"`
+}
+
+// rangeDetail is the detail report of a single range
+type rangeDetail struct {
+	Lo    int         `json:"lo"`
+	Hi    int         `json:"hi"`
+	Count int         `json:"count"`
+	Sum   checked.Int `json:"sum"`
+	IDs   []invalidID `json:"ids"` // the first few in ascending order, Count has them all
+}
+
+// detailReport is the whole detail report
+type detailReport struct {
+	Ranges []rangeDetail `json:"ranges"`
+	Count  int           `json:"count"`
+	Sum    checked.Int   `json:"sum"`
+}
+
+// buildDetail reports the invalid ids of every range, listing at most limit of them per range.
+// the totals are over the merged ranges like the answer, an id in two overlapping ranges counts once
+func buildDetail(ranges [][2]int, r rules, limit int) detailReport {
+	report := detailReport{Ranges: make([]rangeDetail, 0, len(ranges))}
+	for _, rng := range ranges {
+		t := tallyRange(rng, r)
+		d := rangeDetail{Lo: rng[0], Hi: rng[1], Count: t.count, Sum: t.sum, IDs: []invalidID{}}
+		for _, id := range firstInvalid(rng, r, limit) {
+			pattern, repeats, _ := r.match(id)
+			d.IDs = append(d.IDs, invalidID{ID: id, Pattern: pattern, Repeats: repeats})
+		}
+		report.Ranges = append(report.Ranges, d)
+	}
+	for _, rng := range mergeRanges(ranges) {
+		t := tallyRange(rng, r)
+		report.Count += t.count
+		report.Sum = report.Sum.Add(t.sum)
+	}
+	return report
+}
+
+// firstInvalid returns the limit smallest invalid ids of rng in ascending order,
+// generated from the patterns like the closed form so huge ranges cost no more than small ones
+func firstInvalid(rng [2]int, r rules, limit int) []int {
+	var ids []int
+	forEachDigits(rng, r, func(digits, lo, hi int) {
+		if len(ids) >= limit {
+			return
+		}
+
+		// the limit smallest of every pattern length surely hold the limit smallest overall,
+		// an id can repeat patterns of several lengths so drop the duplicates after sorting
+		var found []int
+		for _, patternLen := range buildDivisors(digits) {
+			if !r.allows(digits / patternLen) {
+				continue
+			}
+			repeat, pLo, pHi := patternRange(digits, patternLen, lo, hi, r.base)
+			for p := pLo; p <= pHi && p-pLo < limit; p++ {
+				found = append(found, p*repeat)
+			}
+		}
+		slices.Sort(found)
+		found = slices.Compact(found)
+		ids = append(ids, found[:min(len(found), limit-len(ids))]...)
+	})
+	return ids
+}
+
+// writeTable prints the report as an aligned table, one range per row
+func (report detailReport) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANGE\tINVALID\tSUM\tIDS")
+	for _, d := range report.Ranges {
+		ids := make([]string, len(d.IDs))
+		for i, id := range d.IDs {
+			ids[i] = fmt.Sprintf("%d (%s ×%d)", id.ID, id.Pattern, id.Repeats)
+		}
+		list := strings.Join(ids, ", ")
+		if more := d.Count - len(d.IDs); more > 0 {
+			list += fmt.Sprintf(", ... %d more", more)
+		}
+		fmt.Fprintf(tw, "%d-%d\t%d\t%d\t%s\n", d.Lo, d.Hi, d.Count, d.Sum, list)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t\n", report.Count, report.Sum)
+	return tw.Flush()
+}
+
+// writeJSON prints the report as indented json
+func (report detailReport) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -1,21 +1,31 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+
+	"aoclib/checked"
+	"aoclib/input"
+	"aoclib/interval"
 )
 
 func main() {
 	// validate command line arguments
 	p2 := flag.Bool("p2", false, "enable step 2 logic")
+	base := flag.Int("base", 10, "numeric base the ids are written in to look for patterns")
+	minRepeats := flag.Int("min-repeats", 0, "fewest repeats of a pattern that make an id invalid (default 2)")
+	maxRepeats := flag.Int("max-repeats", 0, "most repeats of a pattern that make an id invalid (default 2, or no limit with -p2)")
+	exactRepeats := flag.Int("repeats", 0, "only exactly this many repeats make an id invalid, same as equal -min-repeats and -max-repeats")
+	brute := flag.Bool("brute", false, "check every id in the ranges instead of generating the invalid ones")
+	verify := flag.Bool("verify", false, "run both the closed form and the brute force and compare them")
+	detail := flag.String("detail", "", "report the invalid IDs of every range instead of the total: table or json")
+	limit := flag.Int("limit", 10, "with -detail, list at most this many invalid IDs per range")
 	flag.Parse()        // parse optional
 	args := flag.Args() // get positional
 	if len(args) != 1 {
@@ -23,70 +33,146 @@ func main() {
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
-
-	// open file
-	file, err := os.Open(args[0])
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
+	r := mirrored
+	if *p2 {
+		r = repeated
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			log.Printf("failed to close file: %s", err)
+	r.base = *base
+	if *exactRepeats != 0 {
+		if *minRepeats != 0 || *maxRepeats != 0 {
+			log.Fatalf("-repeats can't be combined with -min-repeats or -max-repeats")
 		}
-	}()
+		*minRepeats, *maxRepeats = *exactRepeats, *exactRepeats
+	}
+	if *minRepeats != 0 {
+		r.minRepeats = *minRepeats
+	}
+	if *maxRepeats != 0 {
+		r.maxRepeats = *maxRepeats
+	} else if *minRepeats > r.maxRepeats && r.maxRepeats != 0 {
+		r.maxRepeats = 0 // -min-repeats 3 alone means 3 or more, not an error against step 1's 2
+	}
+	if err := r.check(); err != nil {
+		log.Fatalf("invalid rules: %s", err)
+	}
+	if *detail != "" && *detail != "table" && *detail != "json" {
+		log.Fatalf("invalid -detail %q, expected table or json", *detail)
+	}
+	if *limit < 0 {
+		log.Fatalf("invalid -limit %d, must not be negative", *limit)
+	}
 
 	// main logic
-	result, err := process(context.Background(), file, *p2)
+	ranges, err := readRanges(args[0])
 	if err != nil {
 		log.Fatalf("error: %s", err)
 	}
+	if *detail != "" {
+		report := buildDetail(ranges, r, *limit)
+		write := report.writeTable
+		if *detail == "json" {
+			write = report.writeJSON
+		}
+		if err := write(os.Stdout); err != nil {
+			log.Fatalf("error: %s", err)
+		}
+		return
+	}
+	var result checked.Int
+	if *brute || *verify {
+		result, err = sumBrute(context.Background(), mergeRanges(ranges), r)
+		if err != nil {
+			log.Fatalf("error: %s", err)
+		}
+	}
+	if !*brute {
+		closed := sumClosedForm(mergeRanges(ranges), r)
+		if *verify && closed.Cmp(result) != 0 {
+			log.Fatalf("error: closed form got %d but brute force got %d", closed, result)
+		}
+		result = closed
+	}
 	fmt.Printf("Total sum of invalid IDs: %d\n", result)
 }
 
-func process(ctx context.Context, file io.Reader, p2 bool) (int, error) {
-	// create cancellable context from parent
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	// read the first line (expected input format)
-	scanner := bufio.NewScanner(file)
-	scanner.Scan()
-	line := scanner.Text()
+// readRanges reads the comma-separated `lo-hi` ranges of the first line
+func readRanges(fname string) ([][2]int, error) {
+	// real inputs can be one very long line, way past bufio.Scanner's default 64 KiB
+	lines, err := input.Lines(fname)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, &input.ParseError{File: fname, Msg: "empty input, expected comma-separated ranges"}
+	}
+	at := lines[0]
+	line := at.Text
 
 	scopes := strings.Split(line, ",")
-	results := make(chan int, len(scopes)) // channel to collect results
-	var wg sync.WaitGroup                  // to synchronize goroutines
-
+	ranges := make([][2]int, 0, len(scopes))
+	col := 1 // column where the current scope starts, for error messages
 	for _, scope := range scopes {
 		// split the range to left and right
-		var left, right int
-		_, err := fmt.Sscanf(scope, "%d-%d", &left, &right)
+		leftStr, rightStr, found := strings.Cut(scope, "-")
+		if !found {
+			return nil, at.Errorf(col, "invalid range %q, expected lo-hi", scope)
+		}
+		left, err := strconv.Atoi(leftStr)
 		if err != nil {
-			cancel() // signal workers to stop
-			return 0, fmt.Errorf("failed to parse range %q: %w", scope, err)
+			return nil, at.Errorf(col, "invalid range start %q: %w", leftStr, err)
 		}
+		right, err := strconv.Atoi(rightStr)
+		if err != nil {
+			return nil, at.Errorf(col+len(leftStr)+1, "invalid range end %q: %w", rightStr, err)
+		}
+		if left < 0 || left > right {
+			return nil, at.Errorf(col, "invalid range %q, expected 0 <= lo <= hi", scope)
+		}
+		col += len(scope) + 1 // +1 for the comma
+		ranges = append(ranges, [2]int{left, right})
+	}
+	return ranges, nil
+}
+
+// mergeRanges joins overlapping and adjacent ranges, so an id in two of them counts once
+func mergeRanges(ranges [][2]int) [][2]int {
+	set := &interval.Set[int]{}
+	for _, rng := range ranges {
+		set.Insert(rng[0], rng[1])
+	}
+	merged := make([][2]int, 0, set.Count())
+	for iv := range set.All() {
+		merged = append(merged, [2]int{iv.Lo, iv.Hi})
+	}
+	return merged
+}
+
+// sumBrute checks every id of every range, one goroutine per range
+func sumBrute(ctx context.Context, ranges [][2]int, r rules) (checked.Int, error) {
+	// create cancellable context from parent
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
+	results := make(chan checked.Int, len(ranges)) // channel to collect results
+	var wg sync.WaitGroup                          // to synchronize goroutines
+	for _, rng := range ranges {
 		// now process the range concurrently
 		wg.Add(1)
 		go func(left, right int) {
 			defer wg.Done()
-			sum := 0
-			check := isMirrored
-			if p2 {
-				check = isRepeated
-			}
-			for id := left; id <= right; id++ {
+			var sum checked.Int
+			for id := left; id <= right && id >= left; id++ { // id >= left stops at math.MaxInt instead of wrapping
 				select {
 				case <-ctx.Done():
 					return // exit early if context is cancelled
 				default:
-					if check(id) {
-						sum += id
+					if _, _, ok := r.match(id); ok {
+						sum = sum.Add(checked.NewInt(id))
 					}
 				}
 			}
 			results <- sum
-		}(left, right)
+		}(rng[0], rng[1])
 	}
 
 	// close channel once all goroutines are done
@@ -96,84 +182,9 @@ func process(ctx context.Context, file io.Reader, p2 bool) (int, error) {
 	}()
 
 	// collect and sum
-	totalSum := 0
+	var totalSum checked.Int
 	for sum := range results {
-		totalSum += sum
-	}
-	return totalSum, nil
-}
-
-func isMirrored(id int) bool {
-	// count digits
-	digits := 0
-	for temp := id; temp > 0; temp /= 10 {
-		digits++
-	}
-	if digits%2 == 1 {
-		return false // skip odd number of digits
-	}
-
-	// extract halves with power of 10
-	// eg, 1234 / 10*2 = 12, 1234 % 10*2 = 34
-	divisor := 1
-	for i := 0; i < digits/2; i++ {
-		divisor *= 10
-	}
-	return id/divisor == id%divisor
-}
-
-var (
-	divisors   = make(map[int][]int)
-	divisorsMu sync.RWMutex
-)
-
-func isRepeated(id int) bool {
-	// logic is to get the divisors of num digits of id
-	// eg, for 12121212 (num digits 8), divisors are [1,2,4]
-	// then for each divisor, check if the pattern repeats
-	// eg, div 1 is 1->2 so go to next divisor, div 2 12->12->12->12 return true
-	digits := strconv.Itoa(id)
-	lenDigits := len(digits)
-
-	// our global divisors maps are not thread-safe, so need proper	locking
-	divisorsMu.RLock() // try read lock first
-	divs, exists := divisors[lenDigits]
-	divisorsMu.RUnlock()
-	if !exists {
-		divisorsMu.Lock() // now exclusive lock for writing
-		// double check (another goroutine may have built it, avoid waste computation)
-		divs, exists = divisors[lenDigits]
-		if !exists {
-			divs = buildDivisors(lenDigits)
-			divisors[lenDigits] = divs
-		}
-		divisorsMu.Unlock()
-	}
-
-	// now check repeating patterns for each length
-	for _, patternLen := range divs {
-		matches := true
-		pattern := digits[:patternLen] // to match against
-		for i := patternLen; i < lenDigits; i += patternLen {
-			if digits[i:i+patternLen] != pattern {
-				matches = false
-				break
-			}
-		}
-		if matches {
-			return true
-		} // found a repeating pattern, direct return
-	}
-	return false
-}
-
-func buildDivisors(n int) []int {
-	// eg, n=24 => [1,2,3,4,6,8,12]
-	var res []int
-	for i := 1; i <= n/2; i++ {
-		if n%i == 0 {
-			res = append(res, i)
-		}
+		totalSum = totalSum.Add(sum)
 	}
-	return res
+	return totalSum, ctx.Err()
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// rules decide which ids are invalid: written in base, an id is invalid if it is some pattern
+// repeated k times with minRepeats <= k <= maxRepeats, e.g. 12341234 is 1234 repeated twice
+type rules struct {
+	base       int
+	minRepeats int
+	maxRepeats int // 0 for no limit
+}
+
+var (
+	mirrored = rules{base: 10, minRepeats: 2, maxRepeats: 2} // step 1, both halves are the same
+	repeated = rules{base: 10, minRepeats: 2}                // step 2, any pattern at least twice
+)
+
+// check returns an error if the rules make no sense
+func (r rules) check() error {
+	if r.base < 2 || r.base > 36 { // 36 is as far as strconv can write patterns
+		return fmt.Errorf("base must be between 2 and 36, got %d", r.base)
+	}
+	if r.minRepeats < 2 {
+		return fmt.Errorf("min repeats must be at least 2, got %d", r.minRepeats)
+	}
+	if r.maxRepeats != 0 && r.maxRepeats < r.minRepeats {
+		return fmt.Errorf("max repeats %d is below min repeats %d", r.maxRepeats, r.minRepeats)
+	}
+	return nil
+}
+
+// allows reports whether a pattern repeated k times is invalid
+func (r rules) allows(k int) bool {
+	return k >= r.minRepeats && (r.maxRepeats == 0 || k <= r.maxRepeats)
+}
+
+// numDigits returns how many digits n has in the base of the rules
+func (r rules) numDigits(n int) int {
+	digits := 1
+	for n /= r.base; n != 0; n /= r.base {
+		digits++
+	}
+	return digits
+}
+
+// maxDigits is the digit count of math.MaxInt, ids with fewer digits can't reach it
+func (r rules) maxDigits() int {
+	return r.numDigits(math.MaxInt)
+}
+
+var (
+	divisors   = make(map[int][]int)
+	divisorsMu sync.RWMutex
+)
+
+// match checks a single id, returning the shortest pattern that makes it invalid and how often it repeats
+func (r rules) match(id int) (pattern string, repeats int, ok bool) {
+	// logic is to get the divisors of num digits of id
+	// eg, for 12121212 (num digits 8), divisors are [1,2,4]
+	// then for each divisor, check if the pattern repeats
+	// eg, div 1 is 1->2 so go to next divisor, div 2 12->12->12->12 return true
+	digits := strconv.FormatInt(int64(id), r.base)
+	lenDigits := len(digits)
+
+	// our global divisors maps are not thread-safe, so need proper	locking
+	divisorsMu.RLock() // try read lock first
+	divs, exists := divisors[lenDigits]
+	divisorsMu.RUnlock()
+	if !exists {
+		divisorsMu.Lock() // now exclusive lock for writing
+		// double check (another goroutine may have built it, avoid waste computation)
+		divs, exists = divisors[lenDigits]
+		if !exists {
+			divs = buildDivisors(lenDigits)
+			divisors[lenDigits] = divs
+		}
+		divisorsMu.Unlock()
+	}
+
+	// now check repeating patterns for each length, shortest first
+	for _, patternLen := range divs {
+		if !r.allows(lenDigits / patternLen) {
+			continue // eg, exactly 3 repeats skips 1->1->1->1->1->1 but not 11->11->11
+		}
+		if strings.Repeat(digits[:patternLen], lenDigits/patternLen) == digits {
+			return digits[:patternLen], lenDigits / patternLen, true
+		} // found a repeating pattern, direct return
+	}
+	return "", 0, false
+}
+
+func buildDivisors(n int) []int {
+	// eg, n=24 => [1,2,3,4,6,8,12]
+	var res []int
+	for i := 1; i <= n/2; i++ {
+		if n%i == 0 {
+			res = append(res, i)
+		}
+	}
+	return res
+}
@@ -1,20 +1,22 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"math/big"
 	"os"
 	"sync"
+
+	"aoclib/checked"
+	"aoclib/input"
 )
 
 func main() {
 	// validate command line arguments
 	p2 := flag.Bool("p2", false, "enable part two logic")
+	k := flag.Int("k", 0, "how many batteries to turn on per bank (default 2, or 12 with -p2)")
 	flag.Parse()        // parse optional
 	args := flag.Args() // get positional
 	if len(args) != 1 {
@@ -23,67 +25,60 @@ func main() {
 		os.Exit(1)
 	}
 
-	// open file
-	file, err := os.Open(args[0])
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
-	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			log.Printf("failed to close file: %s", err)
+	// how many batteries each part turns on, banks need at least that many
+	if *k == 0 {
+		*k = 2
+		if *p2 {
+			*k = 12
 		}
-	}()
+	}
+	if *k < 1 {
+		log.Fatalf("invalid -k %d, must be at least 1", *k)
+	}
 
 	// main logic
-	result, err := process(context.Background(), file, *p2)
+	result, err := process(context.Background(), args[0], *k)
 	if err != nil {
 		log.Fatalf("error: %s", err)
 	}
 	fmt.Printf("Total output joltage: %d\n", result)
 }
 
-func process(ctx context.Context, file io.ReadSeeker, p2 bool) (int, error) {
+func process(ctx context.Context, fname string, k int) (checked.Int, error) {
 	// create cancellable context from parent
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// first count lines for goroutine channel buffer size
-	lc, err := lineCounter(file)
+	// first read all lines, the count sizes the goroutine channel buffer
+	lines, err := input.Lines(fname)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count lines: %w", err)
+		return checked.Int{}, err
 	}
+	lc := len(lines)
 	fmt.Printf("Amount of battery banks: %d\n", lc)
-	jolts := make(chan int, lc) // channel to collect results
-	var wg sync.WaitGroup       // to synchronize goroutines
+	jolts := make(chan checked.Int, lc) // channel to collect results
+	var wg sync.WaitGroup               // to synchronize goroutines
 	// why not just unbuffered channel? "true parallelism"
 	// with unbuffered, workers that finish early will just wait (eg line 1 goroutine finishes
 	// before main goroutine start receiving, ie, the loop scanner.Scan hasn't done)
 	// with buffered, early finishers can deposit results and exit, allowing more concurrency
 	// BUT this comes with tradeoff where we read the file first, so tradeoff speed needs to be actually
 
-	// reset file pointer to beginning
-	// file must be also io.Seeker to make sure that file is seekable (if not, will panic)
-	_, err = file.Seek(0, io.SeekStart)
-	if err != nil {
-		return 0, fmt.Errorf("failed to seek to beginning of file: %w", err)
-	}
-
-	// read line by line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		digits := scanner.Text()
+	// go line by line
+	for _, line := range lines {
+		digits := line.Text
+		if err := validateBank(digits, k); err != nil {
+			cancel() // signal all goroutines to stop
+			return checked.Int{}, line.Locate(err)
+		}
 
 		// now process the line concurrently
 		wg.Add(1)
 		go func(digits string) {
 			defer wg.Done()
 
-			joltFunc := joltOne
-			if p2 {
-				joltFunc = joltTwo
-			}
-			jolt := joltFunc(digits)
-			fmt.Printf("bank=%s, jolt=%d\n", digits, jolt)
+			jolt, picked := maxSubsequence(digits, k)
+			fmt.Printf("bank=%s, jolt=%d, picked=%v\n", digits, jolt, picked)
 
 			select {
 			case jolts <- jolt:
@@ -94,12 +89,6 @@ func process(ctx context.Context, file io.ReadSeeker, p2 bool) (int, error) {
 		}(digits)
 	}
 
-	// if scanner somehow fails mid-way, prevent goroutine leaks
-	if err := scanner.Err(); err != nil {
-		cancel() // signal all goroutines to stop
-		return 0, fmt.Errorf("failed to scan file: %w", err)
-	}
-
 	// close channel once all goroutines are done
 	go func() {
 		wg.Wait()
@@ -107,103 +96,65 @@ func process(ctx context.Context, file io.ReadSeeker, p2 bool) (int, error) {
 	}()
 
 	// collect and sum
-	totalJolt := 0
+	var totalJolt checked.Int // long jolts add up past an int in a few banks, or are past it already
 	for jolt := range jolts {
-		totalJolt += jolt
+		totalJolt = totalJolt.Add(jolt)
 	}
 	return totalJolt, nil
 }
 
-func joltOne(digits string) int {
-	// try to visualize this yourself hehe
-	// this logic makes our algorithm O(n*m) if not parallelized,
-	// where n is length of digits, m is how many line of digits
-	idxBestLeft, idxBestRight := 0, 1
-	for idxPointer := 1; idxPointer < len(digits); idxPointer++ {
-		// if current digit > best left digit and not the last digit,
-		// make that current digit the best left digit, and the next digit the best right digit
-		if digits[idxPointer] > digits[idxBestLeft] && idxPointer != len(digits)-1 {
-			idxBestLeft, idxBestRight = idxPointer, idxPointer+1
-		} else {
-			// otherwise, check if current digit > best right digit,
-			// if yes, make current digit the best right digit
-			if digits[idxPointer] > digits[idxBestRight] {
-				idxBestRight = idxPointer
-			}
-		}
+// validateBank makes sure the bank only has digits and at least k of them to pick from
+func validateBank(digits string, k int) error {
+	if len(digits) < k {
+		return &input.ParseError{Col: len(digits) + 1, Text: digits, Msg: fmt.Sprintf("bank needs at least %d batteries", k)}
 	}
-
-	// when we index a string, we get byte (value of 50 ASCII for '2')
-	// so byte offset '0' (48 ASCII) to get actual digit value (in byte)
-	digitLeft := digits[idxBestLeft] - '0'
-	digitRight := digits[idxBestRight] - '0'
-	jolt := int(digitLeft*10 + digitRight)
-	return jolt
-}
-
-func joltTwo(digits string) int {
-	// the idea is kind of sliding window, let's say
-	// digits = 2357809, len(digits) = 7, need to find 4 digits to make largest
-	// we need to 'somehow' iterate the digits 4 times to fill in the slot result
-	// i.e. find the max in 2357, then find max in 3578, then 5780, then 7809 (with tricks!!)
-	// see right there? haha greedy clever and this way it will lexicographically largest too
-	const k = 12
-	n := len(digits)
-	result := 0
-
-	idxStart := 0
-	for i := range k {
-		idxEnd := n - k + i // start to end inclusive
-
-		// find the largest digits in this range
-		idxBest := idxStart
-		for j := idxStart; j <= idxEnd; j++ {
-			if digits[j] > digits[idxBest] {
-				idxBest = j
-			}
+	for i := range len(digits) {
+		if digits[i] < '0' || digits[i] > '9' {
+			return &input.ParseError{Col: i + 1, Text: digits, Msg: fmt.Sprintf("invalid battery %q, expected a digit", digits[i])}
 		}
-
-		// add to result
-		digit := digits[idxBest] - '0'
-		result = result*10 + int(digit)
-
-		// TRICKY here, next search starts after the best found
-		idxStart = idxBest + 1
 	}
-
-	return result
+	return nil
 }
 
-// lineCounter is faster line counter using bytes.Count to find the newline characters
-//
-// It's faster because it takes away all the extra logic and buffering required to
-// return whole lines, and takes advantage of some assembly optimized functions
-// offered by the bytes package to search characters in a byte slice.
+// maxDigits is the longest value maxSubsequence builds in an int, 19 digits could pass math.MaxInt
+const maxDigits = 18
+
+// maxSubsequence picks k of the digits, keeping their order, that read as the largest number,
+// and returns that number and the indices it picked. digits must be at least k long.
 //
-// Modified version of code from:
-// Source - https://stackoverflow.com/a/24563853
-// Posted by Mr_Pink, modified by community. See post 'Timeline' for change history
-// Retrieved 2025-12-03, License - CC BY-SA 3.0
-func lineCounter(r io.Reader) (int, error) {
-	buf := make([]byte, 32*1024)
-	count := 0
-	lastCharWasNewline := true
-
-	for {
-		c, err := r.Read(buf)
-		if c > 0 {
-			count += bytes.Count(buf[:c], []byte{'\n'})
-			lastCharWasNewline = buf[c-1] == '\n'
+// the idea is a monotonic stack: walk the digits and keep the best number so far on a stack,
+// a digit bigger than the top of the stack makes a better number if it replaces the top,
+// but only while we may still drop digits (n-k in total), otherwise there are not enough left.
+// eg, digits = 2357809 picking 4 may drop 3: 3 pops 2, 5 pops 3, 7 pops 5, then no drops left,
+// so 8, 0 and 9 just go on top, giving 7809 (lexicographically largest too!)
+// every digit is pushed and popped at most once, so it is O(n) whatever k is
+func maxSubsequence(digits string, k int) (checked.Int, []int) {
+	drop := len(digits) - k // how many digits we can still leave out
+	stack := make([]int, 0, len(digits))
+	for i := range len(digits) {
+		for drop > 0 && len(stack) > 0 && digits[stack[len(stack)-1]] < digits[i] {
+			stack = stack[:len(stack)-1]
+			drop--
 		}
+		stack = append(stack, i)
+	}
+	picked := stack[:k] // drops left over mean the tail never got popped, it is the smallest anyway
 
-		if err == io.EOF {
-			if !lastCharWasNewline && c > 0 {
-				count++
-			}
-			return count, nil
-		}
-		if err != nil {
-			return count, err
+	// when we index a string, we get byte (value of 50 ASCII for '2')
+	// so byte offset '0' (48 ASCII) to get actual digit value (in byte)
+	if k <= maxDigits {
+		value := 0
+		for _, i := range picked {
+			value = value*10 + int(digits[i]-'0')
 		}
+		return checked.NewInt(value), picked
+	}
+
+	// too long for an int, let math/big read the picked digits as one decimal string
+	chosen := make([]byte, k)
+	for j, i := range picked {
+		chosen[j] = digits[i]
 	}
+	value, _ := new(big.Int).SetString(string(chosen), 10) // can't fail, banks are validated digits
+	return checked.FromBig(value), picked
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// the bitset versions keep a row as []uint64, one bit per cell, and do 64 cells per operation:
+// the 8 neighbours of every cell of a word are the words above, on and below shifted a column
+// either way, and adding those 8 one-bit planes with bit-sliced adders gives 64 counts at once,
+// as 4 planes holding bit 0, 1, 2 and 3 of every count
+
+// bitGrid is a 2D grid of rolls as bits, bit c%64 of word c/64 is column c
+type bitGrid struct {
+	rows, cols int
+	words      int // per row
+	bits       [][]uint64
+}
+
+// bitsSupport returns an error if the bitset versions can't follow the rules on g:
+// they only know the 8 cells around of a 2D grid without wrap-around, any threshold and comparison
+func bitsSupport(g *grid, r rules) error {
+	if len(g.dims) != 2 {
+		return fmt.Errorf("%w: the bitset versions only handle 2D grids, got %dD", errUnsupported, len(g.dims))
+	}
+	if r.wrap {
+		return fmt.Errorf("%w: the bitset versions don't wrap around", errUnsupported)
+	}
+	want := moore(2, 1)
+	if len(r.offsets) != len(want) || !slices.EqualFunc(sortedOffsets(r.offsets), want, slices.Equal) {
+		return fmt.Errorf("%w: the bitset versions only handle the 8 cells around", errUnsupported)
+	}
+	return nil
+}
+
+// sortedOffsets returns offsets in the order moore makes them
+func sortedOffsets(offsets [][]int) [][]int {
+	sorted := slices.Clone(offsets)
+	slices.SortFunc(sorted, slices.Compare)
+	return sorted
+}
+
+func newBitGrid(g *grid, r rules) *bitGrid {
+	b := &bitGrid{rows: g.dims[0], cols: g.dims[1]}
+	b.words = (b.cols + 63) / 64
+	b.bits = make([][]uint64, b.rows)
+	for row := range b.rows {
+		b.bits[row] = make([]uint64, b.words)
+		for c := range b.cols {
+			if r.isRoll(g.cells[row*b.cols+c]) {
+				b.bits[row][c/64] |= 1 << (c % 64)
+			}
+		}
+	}
+	return b
+}
+
+// accessible returns, per word of a row, the rolls whose neighbour count passes the rules
+func (b *bitGrid) accessible(r rules, row int) []uint64 {
+	zero := make([]uint64, b.words) // stands in for the rows above the first and below the last
+	above, below := zero, zero
+	if row > 0 {
+		above = b.bits[row-1]
+	}
+	if row < b.rows-1 {
+		below = b.bits[row+1]
+	}
+
+	out := make([]uint64, b.words)
+	for w := range b.words {
+		var s [4]uint64 // bit-sliced counter, s[k] holds bit k of the 64 counts
+		aboveWest, aboveEast := shifted(above, w)
+		west, east := shifted(b.bits[row], w) // not the cell itself, it is no neighbour
+		belowWest, belowEast := shifted(below, w)
+		for _, plane := range [8]uint64{aboveWest, above[w], aboveEast, west, east, belowWest, below[w], belowEast} {
+			addPlane(&s, plane)
+		}
+		out[w] = b.bits[row][w] & compare(s, r.cmp, r.threshold)
+	}
+	return out
+}
+
+// addPlane adds one bit to each of the 64 counts in s, a ripple-carry adder working on all of them at once
+func addPlane(s *[4]uint64, x uint64) {
+	for k := range s {
+		carry := s[k] & x
+		s[k] ^= x
+		x = carry
+	}
+}
+
+// shifted returns word w of line moved so every bit lines up with its west and east neighbour
+func shifted(line []uint64, w int) (west, east uint64) {
+	west, east = line[w]<<1, line[w]>>1
+	if w > 0 {
+		west |= line[w-1] >> 63
+	}
+	if w < len(line)-1 {
+		east |= line[w+1] << 63
+	}
+	return west, east
+}
+
+// compare returns the bits whose 4-bit count in s compares to threshold as cmp says
+func compare(s [4]uint64, cmp string, threshold int) uint64 {
+	// walk the bits from the top: a count is below threshold once it has a 0 where threshold
+	// has a 1 and all higher bits were equal
+	var lt, eq uint64 = 0, ^uint64(0)
+	switch {
+	case threshold < 0:
+		eq = 0
+	case threshold > 15: // counts never pass 8
+		lt, eq = ^uint64(0), 0
+	default:
+		for k := 3; k >= 0; k-- {
+			if threshold>>k&1 == 1 {
+				lt |= eq &^ s[k]
+				eq &= s[k]
+			} else {
+				eq &^= s[k]
+			}
+		}
+	}
+	switch cmp {
+	case "<":
+		return lt
+	case "<=":
+		return lt | eq
+	case ">":
+		return ^(lt | eq)
+	case ">=":
+		return ^lt
+	case "==":
+		return eq
+	}
+	return ^eq
+}
+
+// partOneBits is part one on a bitset
+func partOneBits(g *grid, r rules) (int, error) {
+	if err := bitsSupport(g, r); err != nil {
+		return 0, err
+	}
+	b := newBitGrid(g, r)
+	total := 0
+	for row := range b.rows {
+		for _, word := range b.accessible(r, row) {
+			total += bits.OnesCount64(word)
+		}
+	}
+	return total, nil
+}
+
+// peelBits is part two on a bitset: every wave computes the accessible mask of all rows,
+// then clears it from the rolls in one go. O(waves * cells / 64), no worklist needed
+func peelBits(g *grid, r rules) ([][]int, error) {
+	if err := bitsSupport(g, r); err != nil {
+		return nil, err
+	}
+	b := newBitGrid(g, r)
+	var waves [][]int
+	for {
+		masks := make([][]uint64, b.rows) // all rows first, the wave removes them together
+		var cells []int
+		for row := range b.rows {
+			masks[row] = b.accessible(r, row)
+			for w, word := range masks[row] {
+				for ; word != 0; word &= word - 1 {
+					cells = append(cells, row*b.cols+w*64+bits.TrailingZeros64(word))
+				}
+			}
+		}
+		if len(cells) == 0 {
+			return waves, nil
+		}
+		for row := range b.rows {
+			for w := range b.words {
+				b.bits[row][w] &^= masks[row][w]
+			}
+		}
+		waves = append(waves, cells)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+)
+
+// colours of the animation, by palette index
+const (
+	cellEmpty = iota
+	cellRoll
+	cellRemoving
+	cellRemoved
+)
+
+var palette = color.Palette{
+	cellEmpty:    color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, // dark background
+	cellRoll:     color.RGBA{0xf5, 0xf0, 0xe1, 0xff}, // paper white
+	cellRemoving: color.RGBA{0xe6, 0x39, 0x46, 0xff}, // red, going this wave
+	cellRemoved:  color.RGBA{0x45, 0x47, 0x5a, 0xff}, // grey, gone before
+}
+
+// renderGIF animates the waves of m: the starting layout, one frame per wave with the rolls
+// it removes in red and the ones removed before in grey, then the stable core held a bit longer.
+// every cell is cellSize pixels square, delay is in hundredths of a second per frame
+func renderGIF(fname string, m waveMap, cellSize, delay int) error {
+	anim := &gif.GIF{}
+	for frame := 0; frame <= len(m.Waves)+1; frame++ {
+		anim.Image = append(anim.Image, m.frame(frame, cellSize))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	anim.Delay[len(anim.Delay)-1] = 4 * delay // linger on the result before looping
+
+	file, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("failed to create gif: %w", err)
+	}
+	if err := gif.EncodeAll(file, anim); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write gif: %w", err)
+	}
+	return file.Close()
+}
+
+// frame draws the grid as it is during wave (0 before any wave, past the last for the end result).
+// grids of more than 2 dimensions show their layers side by side, a cell apart
+func (m waveMap) frame(wave, cellSize int) *image.Paletted {
+	dims := m.grid.dims
+	rows, cols := dims[len(dims)-2], dims[len(dims)-1]
+	layers := 0
+	if rows*cols > 0 {
+		layers = len(m.waveOf) / (rows * cols)
+	}
+	width := max(layers*(cols+1)-1, 0)
+	img := image.NewPaletted(image.Rect(0, 0, width*cellSize, rows*cellSize), palette)
+	for i, n := range m.waveOf {
+		state := uint8(cellRoll)
+		switch {
+		case n < 0:
+			state = cellEmpty
+		case n == 0: // core, never removed
+		case n < wave:
+			state = cellRemoved
+		case n == wave:
+			state = cellRemoving
+		}
+		if state == cellEmpty {
+			continue // image starts out all index 0
+		}
+		layer, row, c := i/(rows*cols), i/cols%rows, i%cols
+		x0, y0 := (layer*(cols+1)+c)*cellSize, row*cellSize
+		for y := y0; y < y0+cellSize; y++ {
+			for x := x0; x < x0+cellSize; x++ {
+				img.SetColorIndex(x, y, state)
+			}
+		}
+	}
+	return img
+}
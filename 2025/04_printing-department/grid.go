@@ -0,0 +1,123 @@
+package main
+
+import (
+	"aoclib/input"
+)
+
+// grid is an N-dimensional grid of cells, stored flat with the last axis varying fastest:
+// the puzzle is 2D (row, col), stacking 2D layers makes it 3D (layer, row, col), and so on
+type grid struct {
+	dims    []int // size of every axis, outermost first
+	strides []int // how far apart neighbours along each axis are in cells
+	cells   []byte
+}
+
+func newGrid(dims []int, cells []byte) *grid {
+	g := &grid{dims: dims, strides: make([]int, len(dims)), cells: cells}
+	stride := 1
+	for axis := len(dims) - 1; axis >= 0; axis-- {
+		g.strides[axis] = stride
+		stride *= dims[axis]
+	}
+	return g
+}
+
+// coords returns the position of cell i, one coordinate per axis
+func (g *grid) coords(i int) []int {
+	coords := make([]int, len(g.dims))
+	for axis, size := range g.dims {
+		coords[axis] = i / g.strides[axis] % size
+	}
+	return coords
+}
+
+// rowLen is how many cells make up a row, the last axis
+func (g *grid) rowLen() int {
+	return g.dims[len(g.dims)-1]
+}
+
+// readGrid reads a grid of rows of cells. one blank line between blocks of rows stacks them
+// as layers of a 3D grid, two blank lines stack those 3D grids into a 4D one, and so on.
+// every block along an axis must be the same size, so the grid is a box. leading axes of size 1
+// have no separators to show, so they drop out: a single layer is a 2D grid
+func readGrid(fname string, r rules) (*grid, error) {
+	lines, err := input.Lines(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	// sizes[0] is the rows of a layer, sizes[1] the layers of a 3D block...
+	// fixed by the first of each, counts are the separators seen in the current one
+	var sizes, counts []int
+	var cells []byte
+	cols := -1
+	closeBelow := func(level int, at input.Line) error {
+		for l := range level {
+			n := counts[l] + 1
+			if l == len(sizes) {
+				sizes = append(sizes, n)
+			} else if n != sizes[l] && l == 0 {
+				return at.Errorf(1, "layer ending here has %d rows, expected %d like the first", n, sizes[l])
+			} else if n != sizes[l] && l == 1 {
+				return at.Errorf(1, "stack ending here has %d layers, expected %d like the first", n, sizes[l])
+			} else if n != sizes[l] {
+				return at.Errorf(1, "block ending here has %d parts separated by %d blank lines, expected %d like the first", n, l, sizes[l])
+			}
+			counts[l] = 0
+		}
+		return nil
+	}
+
+	blank := -1 // blank lines before the current one, -1 before the first row
+	var last input.Line
+	for _, at := range lines {
+		line := at.Text
+		if line == "" {
+			if blank >= 0 {
+				blank++
+			}
+			continue
+		}
+
+		// ragged rows would make neighbour lookups go out of range
+		if cols >= 0 && len(line) != cols {
+			return nil, at.Errorf(min(len(line), cols)+1, "row has %d cells, expected %d like the first row", len(line), cols)
+		}
+		for i := range len(line) {
+			if !r.isRoll(line[i]) && line[i] != '.' {
+				return nil, at.Errorf(i+1, "invalid cell %q, expected '.' or one of %q", line[i], r.occupied)
+			}
+		}
+
+		// a row after n blank lines closes the blocks of every level below n
+		if blank >= 0 {
+			for len(counts) <= blank {
+				counts = append(counts, 0)
+			}
+			if err := closeBelow(blank, at); err != nil {
+				return nil, err
+			}
+			counts[blank]++
+		}
+		blank = 0
+		cols = len(line)
+		cells = append(cells, line...) // byte for efficiency (also we already know it is ASCII)
+		last = at
+	}
+	if cols < 0 {
+		return newGrid([]int{0, 0}, nil), nil
+	}
+	if err := closeBelow(len(counts), last); err != nil {
+		return nil, err
+	}
+
+	// sizes go innermost first, dims outermost first
+	dims := []int{cols}
+	for _, n := range sizes {
+		dims = append([]int{n}, dims...)
+	}
+	if len(dims) == 1 {
+		dims = []int{1, cols} // a single row
+	}
+	return newGrid(dims, cells), nil
+}
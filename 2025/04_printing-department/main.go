@@ -1,168 +1,246 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
+
+	"aoclib/versions"
 )
 
 func main() {
 	// validate command line arguments
 	p2 := flag.Bool("p2", false, "enable part two logic")
+	version := flag.String("v", "", "logic version, see -list (default 1, or 2 with -p2)")
+	list := flag.Bool("list", false, "list available versions and exit")
+	verify := flag.Bool("verify", false, "also run the other versions of the same part that handle the rules and compare")
+	neighbourhood := flag.String("neighbourhood", "moore", "cells around a roll that count: moore (square) or von-neumann (diamond)")
+	radius := flag.Int("radius", 1, "how far the neighbourhood reaches")
+	offsets := flag.String("offsets", "", "custom neighbourhood, an offset per axis outermost first, eg \"-1,0;1,0\" for row,col, overrides -neighbourhood")
+	threshold := flag.Int("threshold", -1, "neighbour count to compare against (default 4, or half the 3^N-1 cells around in N dimensions)")
+	cmp := flag.String("cmp", puzzleRules.cmp, "a roll is accessible when its neighbour count <, <=, >, >=, == or != the threshold")
+	occupied := flag.String("rolls", puzzleRules.occupied, "characters of cells holding a roll, other than '.'")
+	wrap := flag.Bool("wrap", false, "wrap around the grid edges like a torus")
+	gifPath := flag.String("gif", "", "instead of the amount, animate the part two waves into this gif file")
+	cellSize := flag.Int("cell", 8, "with -gif, size of a grid cell in pixels")
+	delay := flag.Int("delay", 50, "with -gif, time per frame in hundredths of a second")
+	waves := flag.String("waves", "", "instead of the amount, show the part two wave every roll is removed in: grid or json (cells are coordinates from 0, outermost first)")
 	flag.Parse()        // parse optional
 	args := flag.Args() // get positional
+	if *list {
+		if err := versionTable(nil, rules{}, false).Fprint(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if len(args) != 1 {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <input file>\n", os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	// read file into memory (variable)
-	grid, err := readFileAs2DGrid(args[0])
+	// the rules of accessibility, the puzzle ones unless changed
+	r := rules{threshold: *threshold, cmp: *cmp, occupied: *occupied, wrap: *wrap}
+	if *radius < 1 {
+		log.Fatalf("invalid -radius %d, must be at least 1", *radius)
+	}
+
+	// read file into memory (variable), as 2D or stacked layers of it
+	g, err := readGrid(args[0], r)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// main logic
-	process := partOne
-	if *p2 {
-		process = partTwo
+	// the neighbourhood needs to know how many dimensions there are
+	n := len(g.dims)
+	switch {
+	case *offsets != "":
+		if r.offsets, err = parseOffsets(*offsets, n); err != nil {
+			log.Fatal(err)
+		}
+	case *neighbourhood == "moore":
+		r.offsets = moore(n, *radius)
+	case *neighbourhood == "von-neumann":
+		r.offsets = vonNeumann(n, *radius)
+	default:
+		log.Fatalf("invalid -neighbourhood %q, expected moore or von-neumann", *neighbourhood)
 	}
-	result, err := process(grid)
-	if err != nil {
+	if r.threshold < 0 {
+		r.threshold = scaledThreshold(n)
+	}
+	if err := r.check(); err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("Accessible paper rolls amount: %d\n", result)
-}
 
-func readFileAs2DGrid(fname string) ([][]byte, error) {
-	// open file
-	file, err := os.Open(fname)
-	if err != nil {
-		return nil, err
+	if *gifPath != "" {
+		if *cellSize < 1 || *delay < 0 {
+			log.Fatalf("invalid -cell %d or -delay %d, cells need at least a pixel and delays can't be negative", *cellSize, *delay)
+		}
+		m := buildWaveMap(g, r, peel(g, r))
+		if err := renderGIF(*gifPath, m, *cellSize, *delay); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Wrote %d waves to %s\n", len(m.Waves), *gifPath)
 	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil {
-			err = cerr
+	if *waves != "" {
+		if err := exportWaves(os.Stdout, g, r, *waves); err != nil {
+			log.Fatal(err)
 		}
-	}()
-
-	// read line by line
-	var grid [][]byte // byte for efficiency (also we already know it is ASCII)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		row := []byte(line)
-		grid = append(grid, row)
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if *gifPath != "" || *waves != "" {
+		return
 	}
 
-	return grid, nil
-}
-
-func isRoll(char byte) bool {
-	return char == '@'
+	// main logic
+	if *version == "" {
+		*version = "1"
+		if *p2 {
+			*version = "2"
+		}
+	}
+	result, err := versionTable(g, r, *verify).Execute(*version, args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(result)
 }
 
-var directions = [8][2]int{
-	{-1, 0},  // up
-	{-1, 1},  // up-right
-	{0, 1},   // right
-	{1, 1},   // down-right
-	{1, 0},   // down
-	{1, -1},  // down-left
-	{0, -1},  // left
-	{-1, -1}, // up-left
-}
+// the versions work on the grid and rules main already set up, so the table is built after them
+type (
+	counter func(*grid, rules) (int, error)     // part one
+	peeler  func(*grid, rules) ([][]int, error) // part two, the cells every wave removed
+)
 
-func countAdjacentRolls(grid [][]byte, r, c int) int {
-	count := 0
-	for _, dir := range directions {
-		nr, nc := r+dir[0], c+dir[1]
-		if nr >= 0 && nr < len(grid) &&
-			nc >= 0 && nc < len(grid[r]) &&
-			isRoll(grid[nr][nc]) {
-			count++
-			if count >= 4 {
-				break // stop early
+// errUnsupported is returned by versions that can't follow the rules, -verify skips those
+var errUnsupported = errors.New("unsupported")
+
+// versionTable lists the ways to solve g under r, with verify every version compares its answer
+// with the other versions of the same part
+func versionTable(g *grid, r rules, verify bool) versions.Table {
+	counters := []counter{partOne, partOneBits}
+	peelers := []peeler{
+		func(g *grid, r rules) ([][]int, error) { return peel(g, r), nil },
+		func(g *grid, r rules) ([][]int, error) { return peelRescan(g, r), nil },
+		peelBits,
+	}
+	one := func(k int) func(string) (string, error) {
+		return func(string) (string, error) {
+			result, err := counters[k](g, r)
+			if err != nil {
+				return "", err
+			}
+			for _, other := range counters {
+				if !verify {
+					break
+				}
+				n, err := other(g, r)
+				if errors.Is(err, errUnsupported) {
+					continue
+				} else if err != nil {
+					return "", err
+				}
+				if n != result {
+					return "", fmt.Errorf("versions disagree: %d and %d accessible rolls", result, n)
+				}
 			}
+			return fmt.Sprintf("Accessible paper rolls amount: %d\n", result), nil
 		}
 	}
-	return count
+	two := func(k int) func(string) (string, error) {
+		return func(string) (string, error) {
+			others := peelers
+			if !verify {
+				others = nil
+			}
+			return partTwo(g, r, peelers[k], others)
+		}
+	}
+	return versions.Table{
+		{Name: "1", Description: "part one, count the neighbours of every roll", Complexity: "O(cells*offsets)", Method: versions.Exact, Run: one(0)},
+		{Name: "1b", Description: "part one on a bitset, 64 counts at a time with bit-sliced adders (2D, 8 neighbours)", Complexity: "O(cells/64)", Method: versions.Exact, Run: one(1)},
+		{Name: "2", Description: "part two, peel incrementally from a worklist of rolls whose neighbours changed", Complexity: "O(cells*offsets)", Method: versions.Exact, Run: two(0)},
+		{Name: "2a", Description: "part two, rescan every remaining roll each wave", Complexity: "O(waves*cells*offsets)", Method: versions.Exact, Run: two(1)},
+		{Name: "2b", Description: "part two on a bitset, waves as mask operations (2D, 8 neighbours)", Complexity: "O(waves*cells/64)", Method: versions.Exact, Run: two(2)},
+	}
 }
 
 // brute force to the rescue haha
-func partOne(grid [][]byte) (int, error) {
-	resultChan := make(chan int, len(grid))
+func partOne(g *grid, r rules) (int, error) {
+	rowLen := g.rowLen()
+	rows := 0
+	if rowLen > 0 {
+		rows = len(g.cells) / rowLen
+	}
+	resultChan := make(chan int, rows)
 
-	for r := range len(grid) {
-		go func(r int) {
+	for row := range rows {
+		go func(row int) {
 			result := 0
-			for c := range len(grid[r]) {
-				if !isRoll(grid[r][c]) {
+			for i := row * rowLen; i < (row+1)*rowLen; i++ {
+				if !r.isRoll(g.cells[i]) {
 					continue
 				}
-				if countAdjacentRolls(grid, r, c) < 4 {
+				if r.accessible(r.countAdjacentRolls(g, i)) {
 					result++
 				}
 			}
 			resultChan <- result
-		}(r)
+		}(row)
 	}
 
 	// collect results
 	total := 0
-	for range grid {
+	for range rows {
 		total += <-resultChan
 	}
 	return total, nil
 }
 
-func partTwo(grid [][]byte) (int, error) {
-	// read and store where rolls are (initially)
-	rolls := make([][2]int, 0)
-	for r := range len(grid) {
-		for c := range len(grid[r]) {
-			if isRoll(grid[r][c]) {
-				rolls = append(rolls, [2]int{r, c})
-			}
+// partTwo peels g with peel, printing every wave, and checks the waves against every one of others
+func partTwo(g *grid, r rules, peel peeler, others []peeler) (string, error) {
+	rolls := 0
+	for _, cell := range g.cells {
+		if r.isRoll(cell) {
+			rolls++
 		}
 	}
 
-	// while loop until there is no roll to remove
-	result := 0
-	removed := make([][2]int, len(rolls)) // preallocate
-	stayed := make([][2]int, len(rolls))
-	for {
-		removed, stayed = removed[:0], stayed[:0] // reset slices
-
-		// check all rolls in current iteration
-		for _, pos := range rolls {
-			if countAdjacentRolls(grid, pos[0], pos[1]) < 4 {
-				removed = append(removed, pos)
-			} else {
-				stayed = append(stayed, pos)
-			}
+	waves, err := peel(g, r)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range others {
+		got, err := other(g, r)
+		if errors.Is(err, errUnsupported) {
+			continue
+		} else if err != nil {
+			return "", err
 		}
-
-		// break loop as there are no roll to remove
-		if len(removed) == 0 {
-			break
+		if !slices.EqualFunc(waves, got, slices.Equal) {
+			return "", fmt.Errorf("versions disagree on the rolls removed in the waves")
 		}
+	}
 
-		// update rolls and grid for next iteration
-		fmt.Printf("Removed %d from %d rolls\n", len(removed), len(rolls))
-		for _, pos := range removed {
-			grid[pos[0]][pos[1]] = '.' // mark as removed
-		}
-		result += len(removed)
-		rolls, stayed = stayed, rolls
-		// we do swap here because stayed will be reset in next slices
-		// if we do rolls = stayed, then rolls itself will be lost
+	result := 0
+	for _, cells := range waves {
+		fmt.Printf("Removed %d from %d rolls\n", len(cells), rolls-result)
+		result += len(cells)
+	}
+	return fmt.Sprintf("Accessible paper rolls amount: %d\n", result), nil
+}
+
+// exportWaves writes the wave map of part two in format, grid or json
+func exportWaves(w io.Writer, g *grid, r rules, format string) error {
+	m := buildWaveMap(g, r, peel(g, r))
+	switch format {
+	case "grid":
+		return m.writeGrid(w)
+	case "json":
+		return m.writeJSON(w)
 	}
-	return result, nil
+	return fmt.Errorf("invalid -waves %q, expected grid or json", format)
 }
@@ -0,0 +1,116 @@
+package main
+
+import "slices"
+
+// part two removes every accessible roll at once, which can make more rolls accessible,
+// over and over until nothing changes. both versions below return the cells each wave removed,
+// in reading order, rolls never removed are the stable core.
+
+// peel only looks at rolls whose neighbours changed, k-core style:
+// count the neighbours of every roll once, then removing a roll takes one off the count of every roll
+// that had it as neighbour, and only those rolls can change from inaccessible to accessible (or back,
+// for comparisons like >). every removal touches each offset once, so it is O(rolls * offsets)
+// however many waves there are
+func peel(g *grid, r rules) [][]int {
+	counts := make([]int, len(g.cells)) // neighbour rolls still there
+	removed := make([]bool, len(g.cells))
+	var wave []int
+	for i, cell := range g.cells {
+		if r.isRoll(cell) {
+			counts[i] = r.countAdjacentRolls(g, i)
+			if r.accessible(counts[i]) {
+				wave = append(wave, i)
+			}
+		}
+	}
+
+	// the roll that has this one at off is at -off from here
+	back := make([][]int, len(r.offsets))
+	for k, off := range r.offsets {
+		back[k] = make([]int, len(off))
+		for axis, d := range off {
+			back[k][axis] = -d
+		}
+	}
+
+	var waves [][]int
+	touched := make([]int, len(g.cells)) // wave number a roll was last touched in, to queue it once
+	for len(wave) > 0 {
+		slices.Sort(wave) // flat indices sort in reading order
+		waves = append(waves, wave)
+		// mark the whole wave first, rolls removed together don't count down each other
+		for _, i := range wave {
+			removed[i] = true
+		}
+		var next []int
+		for _, i := range wave {
+			for _, off := range back {
+				j, ok := r.neighbour(g, i, off)
+				if !ok || !r.isRoll(g.cells[j]) || removed[j] {
+					continue
+				}
+				counts[j]--
+				if touched[j] != len(waves) {
+					touched[j] = len(waves)
+					next = append(next, j)
+				}
+			}
+		}
+
+		// rolls nobody touched kept their count, so they can't have become accessible
+		wave = nil
+		for _, j := range next {
+			if r.accessible(counts[j]) {
+				wave = append(wave, j)
+			}
+		}
+	}
+	return waves
+}
+
+// peelRescan is the straightforward version: every wave checks every remaining roll again,
+// O(waves * rolls * offsets). slow on big grids, kept to check peel against with -verify
+func peelRescan(g *grid, r rules) [][]int {
+	// work on a copy, removed rolls are marked in the cells
+	g = newGrid(g.dims, slices.Clone(g.cells))
+
+	// read and store where rolls are (initially)
+	rolls := make([]int, 0)
+	for i, cell := range g.cells {
+		if r.isRoll(cell) {
+			rolls = append(rolls, i)
+		}
+	}
+
+	// while loop until there is no roll to remove
+	var waves [][]int
+	removed := make([]int, len(rolls)) // preallocate
+	stayed := make([]int, len(rolls))
+	for {
+		removed, stayed = removed[:0], stayed[:0] // reset slices
+
+		// check all rolls in current iteration
+		for _, i := range rolls {
+			if r.accessible(r.countAdjacentRolls(g, i)) {
+				removed = append(removed, i)
+			} else {
+				stayed = append(stayed, i)
+			}
+		}
+
+		// break loop as there are no roll to remove
+		if len(removed) == 0 {
+			break
+		}
+
+		// update rolls and grid for next iteration
+		for _, i := range removed {
+			g.cells[i] = 0 // mark as removed, not '.' as -rolls could make that a roll
+		}
+		waves = append(waves, slices.Clone(removed)) // rolls were in reading order, so removed is too
+		rolls, stayed = stayed, rolls
+		// we do swap here because stayed will be reset in next slices
+		// if we do rolls = stayed, then rolls itself will be lost
+	}
+	return waves
+}
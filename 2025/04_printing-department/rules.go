@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aoclib/intmath"
+)
+
+// rules decide which cells hold rolls and when a roll is accessible:
+// count the rolls at the offsets around it (its neighbourhood) and compare that to threshold
+type rules struct {
+	offsets   [][]int // neighbours, one offset per axis of the grid, outermost first
+	threshold int
+	cmp       string // count cmp threshold makes a roll accessible: <, <=, >, >=, == or !=
+	occupied  string // characters of cells holding a roll, any other cell is empty
+	wrap      bool   // the grid is a torus, neighbours past an edge come from the other side
+}
+
+// puzzleRules is the puzzle: fewer than 4 rolls in the 8 cells around, offsets depend on the grid
+var puzzleRules = rules{threshold: 4, cmp: "<", occupied: "@"}
+
+// scaledThreshold is the puzzle's 4 out of 8 carried over to n dimensions: half the 3^n-1 cells around
+func scaledThreshold(n int) int {
+	return (intmath.Pow(3, uint(n)) - 1) / 2
+}
+
+// moore returns the offsets of the n-dimensional cube of cells within radius around a cell,
+// (2*radius+1)^n-1 of them, 8 for a square of radius 1
+func moore(n, radius int) [][]int {
+	offsets := [][]int{{}}
+	for range n {
+		var longer [][]int
+		for _, off := range offsets {
+			for d := -radius; d <= radius; d++ {
+				longer = append(longer, append(slices.Clone(off), d))
+			}
+		}
+		offsets = longer
+	}
+	return slices.DeleteFunc(offsets, func(off []int) bool {
+		return !slices.ContainsFunc(off, func(d int) bool { return d != 0 })
+	})
+}
+
+// vonNeumann returns the offsets of the cells within radius steps along the axes, 4 for a square of radius 1
+func vonNeumann(n, radius int) [][]int {
+	return slices.DeleteFunc(moore(n, radius), func(off []int) bool {
+		steps := 0
+		for _, d := range off {
+			steps += intmath.Abs(d)
+		}
+		return steps > radius
+	})
+}
+
+// parseOffsets reads a custom neighbourhood like "-1,0;1,0;0,-2" for n axes, outermost first
+func parseOffsets(s string, n int) ([][]int, error) {
+	var offsets [][]int
+	for _, tuple := range strings.Split(s, ";") {
+		parts := strings.Split(tuple, ",")
+		if len(parts) != n {
+			return nil, fmt.Errorf("invalid offset %q, expected %d numbers for a %dD grid", tuple, n, n)
+		}
+		off := make([]int, n)
+		for axis, part := range parts {
+			d, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid offset %q: %w", tuple, err)
+			}
+			off[axis] = d
+		}
+		if !slices.ContainsFunc(off, func(d int) bool { return d != 0 }) {
+			return nil, fmt.Errorf("invalid offset %q, a cell is not its own neighbour", tuple)
+		}
+		offsets = append(offsets, off)
+	}
+	return offsets, nil
+}
+
+// check returns an error if the rules make no sense
+func (r rules) check() error {
+	if len(r.offsets) == 0 {
+		return fmt.Errorf("neighbourhood has no cells")
+	}
+	switch r.cmp {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return fmt.Errorf("invalid comparison %q, expected <, <=, >, >=, == or !=", r.cmp)
+	}
+	if r.occupied == "" {
+		return fmt.Errorf("no characters hold a roll")
+	}
+	return nil
+}
+
+func (r rules) isRoll(char byte) bool {
+	return strings.IndexByte(r.occupied, char) >= 0
+}
+
+// accessible reports whether a roll with count neighbouring rolls can be reached
+func (r rules) accessible(count int) bool {
+	switch r.cmp {
+	case "<":
+		return count < r.threshold
+	case "<=":
+		return count <= r.threshold
+	case ">":
+		return count > r.threshold
+	case ">=":
+		return count >= r.threshold
+	case "==":
+		return count == r.threshold
+	}
+	return count != r.threshold
+}
+
+// neighbour returns the cell at offset off from cell i, ok is false if that is off the grid
+func (r rules) neighbour(g *grid, i int, off []int) (int, bool) {
+	j := 0
+	for axis, size := range g.dims {
+		pos := i/g.strides[axis]%size + off[axis]
+		if r.wrap {
+			pos = intmath.Mod(pos, size)
+		} else if pos < 0 || pos >= size {
+			return 0, false
+		}
+		j += pos * g.strides[axis]
+	}
+	return j, true
+}
+
+func (r rules) countAdjacentRolls(g *grid, i int) int {
+	count := 0
+	for _, off := range r.offsets {
+		if j, ok := r.neighbour(g, i, off); ok && r.isRoll(g.cells[j]) {
+			count++
+		}
+	}
+	return count
+}
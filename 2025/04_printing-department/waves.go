@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// the wave map shows where part two eats into the layout: the wave every roll was removed in,
+// and the rolls that are never removed, the stable core
+
+// wave is the rolls removed in one wave, cells are coordinates from 0, outermost axis first
+type wave struct {
+	Wave  int     `json:"wave"`
+	Cells [][]int `json:"cells"`
+}
+
+// waveMap is the whole history of part two
+type waveMap struct {
+	Rolls     int     `json:"rolls"`
+	Removed   int     `json:"removed"`
+	Core      int     `json:"core"`
+	Waves     []wave  `json:"waves"`
+	CoreCells [][]int `json:"core_cells"`
+
+	grid   *grid
+	waveOf []int // wave each cell was removed in, 0 for the core and -1 for empty cells
+}
+
+// buildWaveMap records the waves of a peeling of g
+func buildWaveMap(g *grid, r rules, removed [][]int) waveMap {
+	m := waveMap{Waves: make([]wave, 0, len(removed)), CoreCells: [][]int{}, grid: g}
+	m.waveOf = make([]int, len(g.cells))
+	for n, cells := range removed {
+		coords := make([][]int, len(cells))
+		for k, i := range cells {
+			m.waveOf[i] = n + 1
+			coords[k] = g.coords(i)
+		}
+		m.Waves = append(m.Waves, wave{Wave: n + 1, Cells: coords})
+		m.Removed += len(cells)
+	}
+	for i, cell := range g.cells {
+		if !r.isRoll(cell) {
+			m.waveOf[i] = -1
+			continue
+		}
+		m.Rolls++
+		if m.waveOf[i] == 0 {
+			m.CoreCells = append(m.CoreCells, g.coords(i))
+		}
+	}
+	m.Core = len(m.CoreCells)
+	return m
+}
+
+// writeGrid prints the layout with the wave number of every roll, # for the core and . for empty cells,
+// laid out like the input with layers apart, then the summary
+func (m waveMap) writeGrid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	width := len(strconv.Itoa(len(m.Waves))) // pad so columns line up past wave 9
+	rowLen := m.grid.rowLen()
+	for start := 0; start < len(m.waveOf); start += rowLen {
+		// as many blank lines as the input had, one between layers, two between 3D blocks...
+		if start > 0 {
+			for axis := len(m.grid.dims) - 3; axis >= 0 && start%m.grid.strides[axis] == 0; axis-- {
+				fmt.Fprintln(bw)
+			}
+		}
+		cells := make([]string, rowLen)
+		for c, n := range m.waveOf[start : start+rowLen] {
+			switch {
+			case n > 0:
+				cells[c] = fmt.Sprintf("%*d", width, n)
+			case n < 0:
+				cells[c] = fmt.Sprintf("%*s", width, ".")
+			default:
+				cells[c] = fmt.Sprintf("%*s", width, "#")
+			}
+		}
+		fmt.Fprintln(bw, strings.Join(cells, " "))
+	}
+	fmt.Fprintln(bw)
+	m.writeSummary(bw)
+	return bw.Flush()
+}
+
+// writeSummary prints how much of the layout each wave took and what is left
+func (m waveMap) writeSummary(w io.Writer) {
+	left := m.Rolls
+	for _, wv := range m.Waves {
+		fmt.Fprintf(w, "Wave %d removed %d from %d rolls\n", wv.Wave, len(wv.Cells), left)
+		left -= len(wv.Cells)
+	}
+	share := 0.0
+	if m.Rolls > 0 {
+		share = 100 * float64(m.Core) / float64(m.Rolls)
+	}
+	fmt.Fprintf(w, "Stable core: %d of %d rolls (%.1f%%) after %d waves\n", m.Core, m.Rolls, share, len(m.Waves))
+}
+
+// writeJSON prints the map as compact json, indenting thousands of coordinates helps nobody
+func (m waveMap) writeJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"slices"
-	"strings"
+
+	"aoclib/checked"
+	"aoclib/input"
+	"aoclib/interval"
 )
 
 func main() {
 	// validate command line arguments
 	p2 := flag.Bool("p2", false, "enable part two logic")
+	verify := flag.Bool("verify", false, "also check every ingredient against every range and compare (part one)")
+	report := flag.Bool("report", false, "list the ranges (by input line) containing each ingredient")
+	overlap := flag.String("overlap", "", "list the ranges (by input line) overlapping `lo-hi`")
+	query := flag.Bool("query", false, "read ingredient IDs from stdin, one per line, and answer fresh or spoiled for each")
 	flag.Parse()        // parse optional
 	args := flag.Args() // get positional
 	if len(args) != 1 {
@@ -21,12 +26,38 @@ func main() {
 		os.Exit(1)
 	}
 
+	// answer IDs from stdin against the ranges, the ingredients in the file are not needed
+	if *query {
+		ranges, _, err := readRanges(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := answerQueries(os.Stdin, os.Stdout, freshSet(ranges)); err != nil {
+			log.Fatalf("error: %s", err)
+		}
+		return
+	}
+
 	// read file into memory (variable)
-	ranges, ingredients, err := readFile(args[0])
+	ranges, lines, ingredients, err := readFile(args[0])
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// which ranges hold what, instead of the answer
+	if *report || *overlap != "" {
+		tree := rangeTree(ranges, lines)
+		if *overlap != "" {
+			err = writeOverlap(os.Stdout, tree, *overlap)
+		} else {
+			err = writeReport(os.Stdout, tree, ingredients)
+		}
+		if err != nil {
+			log.Fatalf("error: %s", err)
+		}
+		return
+	}
+
 	// main logic
 	process := partOne // or partOneBrute
 	if *p2 {
@@ -36,125 +67,111 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *verify && !*p2 {
+		if brute, _ := partOneBrute(ranges, ingredients); brute.Cmp(result) != 0 {
+			log.Fatalf("error: binary search got %d but brute force got %d", result, brute)
+		}
+	}
 	fmt.Printf("Fresh ingredients count: %d\n", result)
 }
 
-func readFile(fname string) ([][2]int, []int, error) {
-	// open file
-	file, err := os.Open(fname)
+// readFile returns the ranges, the input line of every range and the ingredients
+func readFile(fname string) ([][2]int, []int, []int, error) {
+	// two blank-line separated sections: ranges, then ingredients
+	paragraphs, err := readSections(fname)
 	if err != nil {
-		return nil, nil, err
-	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-
-	// read ranges
-	ranges := make([][2]int, 0)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			break
-		} // break on empty line
-
-		var lo, hi int
-		_, err = fmt.Sscanf(line, "%d-%d", &lo, &hi)
-		if err != nil {
-			return nil, nil, err
-		}
-		ranges = append(ranges, [2]int{lo, hi})
+		return nil, nil, nil, err
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, nil, err
+	ranges, lines, err := parseRanges(paragraphs[0])
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	// read ingredients
 	ingredients := make([]int, 0)
-	for scanner.Scan() {
-		line := scanner.Text()
-		var ing int
-		_, err = fmt.Sscanf(line, "%d", &ing)
-		if err != nil {
-			return nil, nil, err
+	if len(paragraphs) == 2 {
+		for _, line := range paragraphs[1] {
+			ing, err := line.Int()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			ingredients = append(ingredients, ing)
 		}
-		ingredients = append(ingredients, ing)
 	}
-	if err := scanner.Err(); err != nil {
+
+	return ranges, lines, ingredients, nil
+}
+
+// readRanges reads only the ranges section, an ingredients section after it is not even parsed
+func readRanges(fname string) ([][2]int, []int, error) {
+	paragraphs, err := readSections(fname)
+	if err != nil {
 		return nil, nil, err
 	}
-
-	return ranges, ingredients, nil
+	return parseRanges(paragraphs[0])
 }
 
-func sortRanges(ranges [][2]int) {
-	// this built-in sort function will sort by:
-	// if return negative, a should come before b
-	// if return positive, a should come after b
-	// if return zero, a and b are equal in sort order
-	slices.SortFunc(ranges, func(a, b [2]int) int {
-		return a[0] - b[0]
-	})
+// readSections splits the file in its ranges and (optional) ingredients sections
+func readSections(fname string) ([][]input.Line, error) {
+	paragraphs, err := input.Paragraphs(fname)
+	if err != nil {
+		return nil, err
+	}
+	if len(paragraphs) == 0 {
+		return nil, &input.ParseError{File: fname, Msg: "no fresh ID ranges in input"}
+	}
+	if len(paragraphs) > 2 {
+		extra := paragraphs[2][0]
+		return nil, extra.Errorf(0, "unexpected third section, expected ranges then ingredients")
+	}
+	return paragraphs, nil
 }
 
-func mergeRanges(ranges [][2]int) [][2]int {
-	// this assumes ranges are already sorted by start
-	// cur: [1-5], next: [3-8] -> overlap
-	// cur: [1-5], next: [6-8] -> adjacent
-	// cur: [1-5], next: [7-8] -> separate
-	merged := make([][2]int, 0, len(ranges)) // careful here, need the zero!
-	cur := ranges[0]
-	for i := 1; i < len(ranges); i++ {
-		next := ranges[i]
-		if next[0] <= cur[1]+1 { // overlap or adjacent
-			if next[1] > cur[1] {
-				cur[1] = next[1]
-			}
-		} else { // separate
-			merged = append(merged, cur)
-			cur = next
+// parseRanges reads lo-hi lines, returning the ranges and the input line of every range
+func parseRanges(section []input.Line) ([][2]int, []int, error) {
+	ranges := make([][2]int, 0, len(section))
+	lines := make([]int, 0, len(section))
+	for _, line := range section {
+		bounds, err := line.Ints("-", 2)
+		if err != nil {
+			return nil, nil, err
+		}
+		lo, hi := bounds[0], bounds[1]
+		if lo > hi {
+			return nil, nil, line.Errorf(1, "range start %d is after its end %d", lo, hi)
 		}
+		ranges = append(ranges, [2]int{lo, hi})
+		lines = append(lines, line.Num)
 	}
-	merged = append(merged, cur) // last range
-	return merged
+	return ranges, lines, nil
 }
 
-func isFreshBinarySearch(id int, ranges [][2]int) bool {
-	left, right := 0, len(ranges)-1
-	for left <= right {
-		mid := left + (right-left)/2
-		r := ranges[mid]
-		if id < r[0] { // before this range, search left
-			right = mid - 1
-		} else if id > r[1] { // after this range, search right
-			left = mid + 1
-		} else { // in this range
-			return true
-		}
+// freshSet merges the ranges into a set, overlapping and adjacent ones become one interval
+func freshSet(ranges [][2]int) *interval.Set[int] {
+	ivs := make([]interval.Interval[int], len(ranges))
+	for i, r := range ranges {
+		ivs[i] = interval.Interval[int]{Lo: r[0], Hi: r[1]}
 	}
-	return false
+	return interval.Of(ivs...)
 }
 
 // binary search solution
-func partOne(ranges [][2]int, ingredients []int) (int, error) {
-	// preprocess ranges: sort and merged
-	sortRanges(ranges)
-	ranges = mergeRanges(ranges)
+func partOne(ranges [][2]int, ingredients []int) (checked.Int, error) {
+	// preprocess ranges: sorted and merged in a set
+	fresh := freshSet(ranges)
 
 	// process ingredients: binary search
 	count := 0
 	for _, ing := range ingredients {
-		if isFreshBinarySearch(ing, ranges) {
+		if fresh.Contains(ing) {
 			count++
 		}
 	}
-	return count, nil
+	return checked.NewInt(count), nil
 }
 
 // brute force solution
-func partOneBrute(ranges [][2]int, ingredients []int) (int, error) {
+func partOneBrute(ranges [][2]int, ingredients []int) (checked.Int, error) {
 	count := 0
 	for _, ing := range ingredients {
 		for _, r := range ranges {
@@ -165,19 +182,16 @@ func partOneBrute(ranges [][2]int, ingredients []int) (int, error) {
 			}
 		}
 	}
-	return count, nil
+	return checked.NewInt(count), nil
 }
 
-func partTwo(ranges [][2]int, ingredients []int) (int, error) {
-	// preprocess ranges: sort and merged
-	sortRanges(ranges)
-	ranges = mergeRanges(ranges)
+func partTwo(ranges [][2]int, ingredients []int) (checked.Int, error) {
+	// preprocess ranges: sorted and merged in a set
+	fresh := freshSet(ranges)
 
-	// we only need the ranges here
-	count := 0
-	for _, r := range ranges {
-		fmt.Printf("Fresh range: %d-%d\n", r[0], r[1])
-		count += r[1] - r[0] + 1
+	// we only need the ranges here, a few ranges spanning the whole int range already overflow the sum
+	for r := range fresh.All() {
+		fmt.Printf("Fresh range: %d-%d\n", r.Lo, r.Hi)
 	}
-	return count, nil
+	return fresh.Len(), nil
 }
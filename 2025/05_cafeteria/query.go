@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"aoclib/interval"
+)
+
+// answerQueries reads one ingredient ID per line from r and writes "<id> fresh" or "<id> spoiled"
+// for each. every answer is flushed right away so a script can ask one ID and wait for the reply,
+// a line that is no ID gets "<text> invalid" so answers stay in step with questions.
+// blank lines are skipped
+func answerQueries(r io.Reader, w io.Writer, fresh *interval.Set[int]) error {
+	scanner := bufio.NewScanner(r)
+	out := bufio.NewWriter(w)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		id, err := strconv.Atoi(text)
+		switch {
+		case err != nil:
+			fmt.Fprintf(out, "%s invalid\n", text)
+		case fresh.Contains(id):
+			fmt.Fprintf(out, "%d fresh\n", id)
+		default:
+			fmt.Fprintf(out, "%d spoiled\n", id)
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read queries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"aoclib/interval"
+)
+
+// rangeTree indexes the ranges as given, unmerged, each tagged with its input line
+func rangeTree(ranges [][2]int, lines []int) *interval.Tree[int, int] {
+	entries := make([]interval.Entry[int, int], len(ranges))
+	for i, r := range ranges {
+		entries[i] = interval.Entry[int, int]{Interval: interval.Interval[int]{Lo: r[0], Hi: r[1]}, Value: lines[i]}
+	}
+	return interval.NewTree(entries)
+}
+
+// describe lists ranges like "line 2 (10-14), line 4 (12-18)", or "-" for none
+func describe(found []interval.Entry[int, int]) string {
+	if len(found) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(found))
+	for i, e := range found {
+		parts[i] = fmt.Sprintf("line %d (%d-%d)", e.Value, e.Lo, e.Hi)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeReport prints every ingredient with the ranges containing it, in input order
+func writeReport(w io.Writer, tree *interval.Tree[int, int], ingredients []int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INGREDIENT\tFRESH\tRANGES")
+	fresh := 0
+	for _, ing := range ingredients {
+		found := tree.Stab(ing)
+		state := "no"
+		if len(found) > 0 {
+			state = "yes"
+			fresh++
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", ing, state, describe(found))
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d/%d\t\n", fresh, len(ingredients))
+	return tw.Flush()
+}
+
+// writeOverlap prints the ranges overlapping the range lo-hi given as text
+func writeOverlap(w io.Writer, tree *interval.Tree[int, int], query string) error {
+	loText, hiText, ok := strings.Cut(query, "-")
+	if !ok {
+		return fmt.Errorf("invalid range %q, expected lo-hi", query)
+	}
+	lo, err := strconv.Atoi(loText)
+	if err != nil {
+		return fmt.Errorf("invalid range %q: %w", query, err)
+	}
+	hi, err := strconv.Atoi(hiText)
+	if err != nil {
+		return fmt.Errorf("invalid range %q: %w", query, err)
+	}
+	if lo > hi {
+		return fmt.Errorf("invalid range %q, start is after end", query)
+	}
+	found := tree.Overlapping(lo, hi)
+	fmt.Fprintf(w, "Ranges overlapping %d-%d: %d\n", lo, hi, len(found))
+	for _, e := range found {
+		fmt.Fprintf(w, "line %d: %d-%d\n", e.Value, e.Lo, e.Hi)
+	}
+	return nil
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
+
+	"aoclib/checked"
+	"aoclib/input"
 )
 
 func main() {
@@ -33,109 +34,111 @@ func main() {
 	fmt.Printf("Total: %d\n", result)
 }
 
-func partOne(fname string) (uint64, error) {
-	// open file
-	file, err := os.Open(fname)
+func partOne(fname string) (checked.Int, error) {
+	lines, err := input.Lines(fname)
 	if err != nil {
-		return 0, err
+		return checked.Int{}, err
 	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
 
-	// read line by line
+	// go line by line
 	syms := make([]byte, 0)
-	nums := make([]uint64, 0)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
+	nums := make([]checked.Int, 0)
+	numsPerLine := -1 // every number line must have as many numbers as the first
+	var symAt input.Line
+	for _, at := range lines {
+		line := strings.TrimSpace(at.Text)
+		if line == "" {
+			return checked.Int{}, at.Errorf(0, "unexpected blank line")
+		}
 
 		// handle last line (math symbols)
 		if line[0] == '*' || line[0] == '+' {
-			syms = parseSymLine(line)
+			syms, err = parseSymLine(at)
+			if err != nil {
+				return checked.Int{}, err
+			}
+			symAt = at
 			break
 		}
 
 		// handle number line(s)
-		for numStr := range strings.FieldsSeq(line) {
-			num, err := strconv.ParseUint(numStr, 10, 64)
-			if err != nil {
-				return 0, err
+		words, cols := at.Fields()
+		for i, numStr := range words {
+			num, err := checked.Parse(numStr)
+			if err != nil || num.Cmp(checked.Int{}) < 0 {
+				return checked.Int{}, at.Errorf(cols[i], "invalid number %q", numStr)
 			}
 			nums = append(nums, num)
 		}
+		if numsPerLine >= 0 && len(words) != numsPerLine {
+			return checked.Int{}, at.Errorf(0, "line has %d numbers, expected %d like the first line", len(words), numsPerLine)
+		}
+		numsPerLine = len(words)
+	}
+	if len(syms) == 0 {
+		return checked.Int{}, &input.ParseError{File: fname, Line: len(lines), Msg: "missing symbol line at the end"}
 	}
-	if err := scanner.Err(); err != nil {
-		return 0, err
+	if len(syms) != numsPerLine {
+		return checked.Int{}, symAt.Errorf(0, "line has %d symbols, expected %d like the number lines", len(syms), numsPerLine)
 	}
 
-	// process numbers and symbols
-	result := uint64(0)
+	// process numbers and symbols, checked as a long column of products easily passes 64 bits
+	var result checked.Int
 	numLines := len(nums) / len(syms)
 	for symIdx, sym := range syms {
-		var innerResult uint64
+		var innerResult checked.Int
 		if sym == '*' {
-			innerResult = 1
+			innerResult = checked.NewInt(1)
 			for i := range numLines {
-				innerResult *= nums[i*len(syms)+symIdx]
+				innerResult = innerResult.Mul(nums[i*len(syms)+symIdx])
 			}
 		} else {
-			innerResult = 0
 			for i := range numLines {
-				innerResult += nums[i*len(syms)+symIdx]
+				innerResult = innerResult.Add(nums[i*len(syms)+symIdx])
 			}
 		}
 		fmt.Printf("%d %c: %d\n", symIdx, sym, innerResult)
-		result += innerResult
+		result = result.Add(innerResult)
 	}
 	return result, nil
 }
 
-func partTwo(fname string) (uint64, error) {
-	// open file
-	file, err := os.Open(fname)
+func partTwo(fname string) (checked.Int, error) {
+	// read all lines, we need them at once to go column by column
+	lines, err := input.Lines(fname)
 	if err != nil {
-		return 0, err
-	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-
-	// read line by line
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		return 0, err
+		return checked.Int{}, err
 	}
 
 	// separate number lines and symbol line
+	if len(lines) < 2 {
+		return checked.Int{}, &input.ParseError{File: fname, Line: len(lines), Msg: "expected number lines followed by a symbol line"}
+	}
 	numLines := lines[:len(lines)-1]
-	if !verifyNumLines(numLines) {
-		return 0, fmt.Errorf("inconsistent line lengths in number lines")
+	if err := verifyNumLines(numLines); err != nil {
+		return checked.Int{}, err
 	}
 	symLine := lines[len(lines)-1]
-	syms := parseSymLine(symLine)
+	syms, err := parseSymLine(symLine)
+	if err != nil {
+		return checked.Int{}, err
+	}
 
 	// process numbers by right-to-left one column at a time
-	lenColumn := len(numLines[0]) // how many chars to process
-	curNums := make([]uint64, 0)  // store current numbers for symbol operation
-	curSymsIdx := len(syms) - 1   // which symbol to use for curNums
-	grandResult := uint64(0)      // final result
+	lenColumn := len(numLines[0].Text) // how many chars to process
+	curNums := make([]checked.Int, 0)  // store current numbers for symbol operation
+	curSymsIdx := len(syms) - 1        // which symbol to use for curNums
+	var grandResult checked.Int        // final result
 	for i := lenColumn - 1; i >= -1; i-- {
 		var curColumn []int
 
 		// handle character column, i == -1 means we are done with digits (and process last symbol)
 		if i >= 0 {
 			for j := range numLines {
-				char := numLines[j][i]
+				char := numLines[j].Text[i]
+				if char != ' ' && (char < '0' || char > '9') {
+					return checked.Int{}, numLines[j].Errorf(i+1, "invalid character %q, expected a digit or space", char)
+				}
 				num := byteToDigit(char)
 				if num >= 0 {
 					curColumn = append(curColumn, num)
@@ -145,53 +148,68 @@ func partTwo(fname string) (uint64, error) {
 
 		// if curColumn is empty, then we math with symbols
 		if len(curColumn) == 0 {
+			if len(curNums) == 0 {
+				continue // extra blank column, nothing to math with
+			}
+			if curSymsIdx < 0 {
+				return checked.Int{}, symLine.Errorf(0, "more number groups than the %d symbols", len(syms))
+			}
 			sym := syms[curSymsIdx]
-			var innerResult uint64
+			var innerResult checked.Int
 			if sym == '*' {
-				innerResult = 1
+				innerResult = checked.NewInt(1)
 				for _, num := range curNums {
-					innerResult *= num
+					innerResult = innerResult.Mul(num)
 				}
 			} else {
-				innerResult = 0
 				for _, num := range curNums {
-					innerResult += num
+					innerResult = innerResult.Add(num)
 				}
 			}
 			fmt.Printf("%d %c: %d\n", curSymsIdx, sym, innerResult)
-			grandResult += innerResult
+			grandResult = grandResult.Add(innerResult)
 			curNums = curNums[:0] // reset for next column
 			curSymsIdx--
 
 			// otherwise, we collect the numbers
 		} else {
-			num := digitsToUint64(curColumn)
+			num := digitsToInt(curColumn)
 			curNums = append(curNums, num)
 			fmt.Printf("col %d: %v -> %d\n", i, curColumn, num)
 		}
 	}
+	if curSymsIdx >= 0 {
+		return checked.Int{}, symLine.Errorf(0, "%d symbols but only %d number groups", len(syms), len(syms)-curSymsIdx-1)
+	}
 
 	return grandResult, nil
 }
 
-func parseSymLine(line string) (syms []byte) {
-	for symStr := range strings.FieldsSeq(line) {
+func parseSymLine(at input.Line) ([]byte, error) {
+	var syms []byte
+	words, cols := at.Fields()
+	for i, symStr := range words {
+		if symStr != "*" && symStr != "+" {
+			return nil, at.Errorf(cols[i], "invalid symbol %q, expected * or +", symStr)
+		}
 		syms = append(syms, symStr[0])
 	}
-	return syms
+	return syms, nil
 }
 
-func verifyNumLines(lines []string) bool {
+// verifyNumLines checks all number lines are as long as the first one,
+// column-wise reading in part two depends on it
+func verifyNumLines(lines []input.Line) error {
 	if len(lines) == 0 {
-		return true
+		return nil
 	}
-	charCount := len(lines[0])
+	charCount := len(lines[0].Text)
 	for i := 1; i < len(lines); i++ {
-		if len(lines[i]) != charCount {
-			return false
+		if n := len(lines[i].Text); n != charCount {
+			return lines[i].Errorf(min(n, charCount)+1, "line has %d characters, expected %d like the first line", n, charCount)
 		}
 	}
-	return true
+	return nil
 }
 
 func byteToDigit(b byte) int {
@@ -201,9 +219,11 @@ func byteToDigit(b byte) int {
 	return int(b - '0')
 }
 
-func digitsToUint64(digits []int) (result uint64) {
+// digitsToInt builds the column number, which has as many digits as there are lines
+func digitsToInt(digits []int) (result checked.Int) {
+	ten := checked.NewInt(10)
 	for _, d := range digits {
-		result = result*10 + uint64(d)
+		result = result.Mul(ten).Add(checked.NewInt(d))
 	}
 	return result
 }
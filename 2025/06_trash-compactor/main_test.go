@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoclib/checked"
+	"aoclib/input"
+)
+
+// FuzzColumns runs both parts, they read the worksheet columns their own way:
+// part one by whitespace separated fields, part two by character column
+func FuzzColumns(f *testing.F) {
+	seed, err := os.ReadFile("test1")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Add([]byte(""))
+	f.Add([]byte("\n"))
+	f.Add([]byte("1 2\n*\n"))
+	f.Add([]byte("12\n 3\n+ \n"))
+	f.Add([]byte("1\n\n+\n"))
+
+	// both parts print every column, which only slows the fuzzer down
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		f.Fatal(err)
+	}
+	os.Stdout = devNull
+	f.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fname := filepath.Join(t.TempDir(), "input")
+		if err := os.WriteFile(fname, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for name, part := range map[string]func(string) (checked.Int, error){"partOne": partOne, "partTwo": partTwo} {
+			if _, err := part(fname); err != nil && !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("%s(%q) failed with %T, want a *input.ParseError: %v", name, data, err, err)
+			}
+		}
+	})
+}
@@ -1,20 +1,36 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+
+	"aoclib/checked"
+	"aoclib/input"
+	"aoclib/versions"
 )
 
 var (
-	filename        *string
-	version         *string
-	versionHandlers = map[string]func(string) (string, error){
-		"1": partOne,
-		"2": partTwo,
+	filename *string
+	version  *string
+	// R is number of lines, W the line width
+	versionTable = versions.Table{
+		{
+			Name:        "1",
+			Description: "part one, track beam positions line by line with a set",
+			Complexity:  "O(R*W)",
+			Method:      versions.Exact,
+			Run:         partOne,
+		},
+		{
+			Name:        "2",
+			Description: "part two, memoized backtracking over splitter lines",
+			Complexity:  "O(R*W)",
+			Method:      versions.Exact,
+			Run:         partTwo,
+		},
 	}
 )
 
@@ -22,17 +38,24 @@ func validateFlags() error {
 	if *filename == "" {
 		return fmt.Errorf("input file name is required")
 	}
-	if _, ok := versionHandlers[*version]; !ok {
-		return fmt.Errorf("invalid version: %s", *version)
+	if _, err := versionTable.Lookup(*version); err != nil {
+		return err
 	}
 	return nil
 }
 
 func main() {
 	// program input
-	version = flag.String("v", "1", "logic version: only 1 available")
+	version = flag.String("v", "1", "logic version, see -list")
 	filename = flag.String("f", "", "input file name (required)")
+	list := flag.Bool("list", false, "list available versions and exit")
 	flag.Parse()
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			log.Fatalf("Error: %v\n", err)
+		}
+		return
+	}
 	if err := validateFlags(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		flag.Usage()
@@ -40,8 +63,7 @@ func main() {
 	}
 
 	// main logic
-	handler := versionHandlers[*version]
-	result, err := handler(*filename)
+	result, err := versionTable.Execute(*version, *filename)
 	if err != nil {
 		log.Fatalf("Error: %v\n", err)
 	}
@@ -49,38 +71,28 @@ func main() {
 }
 
 func partOne(filename string) (string, error) {
-	// open file
-	file, err := os.Open(filename)
+	lines, err := input.Lines(filename)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close() // error not checked as file only for reading
 
 	// find beam origin 'S': ideally on the first line
-	beams := NewSet[int]()
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		beam := strings.IndexByte(line, 'S')
-		if beam >= 0 {
-			beams.Add(beam)
-			break
-		}
-	}
-	if err := scanner.Err(); err != nil {
+	row, origin, err := findOrigin(filename, lines)
+	if err != nil {
 		return "", err
 	}
-	if beams.Size() == 0 {
-		return "", fmt.Errorf("no beam origin 'S' found in the input")
-	}
+	beams := NewSet[int]()
+	beams.Add(origin)
 
-	// now split the beam(s) while reading line by line
+	// now split the beam(s) line by line
 	splitCount := 0
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range lines[row+1:] {
+		if err := checkLine(line); err != nil {
+			return "", err
+		}
 
 		// get splitters positions
-		splitters := getSplitters(line, '^')
+		splitters := getSplitters(line.Text, '^')
 		if splitters.Size() == 0 {
 			continue
 		}
@@ -96,13 +108,36 @@ func partOne(filename string) (string, error) {
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
 
 	return fmt.Sprintf("The beam is split %d times\n", splitCount), nil
 }
 
+// findOrigin returns the row and column of the beam origin 'S',
+// checking every line up to it
+func findOrigin(filename string, lines []input.Line) (int, int, error) {
+	for row, line := range lines {
+		if err := checkLine(line); err != nil {
+			return 0, 0, err
+		}
+		if col := strings.IndexByte(line.Text, 'S'); col >= 0 {
+			return row, col, nil
+		}
+	}
+	return 0, 0, &input.ParseError{File: filename, Line: len(lines), Msg: "no beam origin 'S' found in the input"}
+}
+
+// checkLine rejects anything but empty space, splitters and the beam origin
+func checkLine(at input.Line) error {
+	for i := range len(at.Text) {
+		switch at.Text[i] {
+		case '.', '^', 'S':
+		default:
+			return at.Errorf(i+1, "invalid character %q, expected '.', '^' or 'S'", at.Text[i])
+		}
+	}
+	return nil
+}
+
 func getSplitters(s string, b byte) *Set[int] {
 	splitters := NewSet[int]()
 	for i := 0; i < len(s); {
@@ -118,56 +153,42 @@ func getSplitters(s string, b byte) *Set[int] {
 }
 
 func partTwo(filename string) (string, error) {
-	// open file
-	file, err := os.Open(filename)
+	lines, err := input.Lines(filename)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close() // error not checked as file only for reading
 
 	// find beam origin 'S': ideally on the first line
-	var beamOrigin int
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		beamOrigin = strings.IndexByte(line, 'S')
-		if beamOrigin >= 0 {
-			break
-		}
-	}
-	if err := scanner.Err(); err != nil {
+	row, beamOrigin, err := findOrigin(filename, lines)
+	if err != nil {
 		return "", err
 	}
-	if beamOrigin < 0 {
-		return "", fmt.Errorf("no beam origin 'S' found in the input")
-	}
 
 	// instead of processing line by line, we want some kind of backtracking here
-	// so we read all lines first containing splitters
+	// so we collect all lines first containing splitters
 	splittersLines := make([]*Set[int], 0)
-	for scanner.Scan() {
-		line := scanner.Text()
-		splitters := getSplitters(line, '^')
+	for _, line := range lines[row+1:] {
+		if err := checkLine(line); err != nil {
+			return "", err
+		}
+		splitters := getSplitters(line.Text, '^')
 		if splitters.Size() == 0 {
 			continue
 		}
 		splittersLines = append(splittersLines, splitters)
 	}
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
 
-	// memoization for backtracking
-	var backtrack func(index, beam int) int
+	// memoization for backtracking, counts double at every split so they are checked.Int
+	var backtrack func(index, beam int) checked.Int
 	type state struct{ index, beam int }
-	memoization := make(map[state]int)
-	backtrack = func(index, beam int) int {
+	memoization := make(map[state]checked.Int)
+	backtrack = func(index, beam int) checked.Int {
 		// index tells what splitters line we are processing
 		// beam is the current "root" beam position
 
 		// base: reached the end of the lines, count as one valid way
 		if index >= len(splittersLines) {
-			return 1
+			return checked.NewInt(1)
 		}
 		// get value from memo if already computed
 		key := state{index, beam}
@@ -176,9 +197,9 @@ func partTwo(filename string) (string, error) {
 		}
 
 		// if we have a split here, count on branch left and right
-		var count int
+		var count checked.Int
 		if splittersLines[index].Contains(beam) {
-			count = backtrack(index+1, beam-1) + backtrack(index+1, beam+1)
+			count = backtrack(index+1, beam-1).Add(backtrack(index+1, beam+1))
 		} else { // otherwise continue straight
 			count = backtrack(index+1, beam)
 		}
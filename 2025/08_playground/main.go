@@ -1,39 +1,72 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
 	"sort"
+	"unsafe"
+
+	"aoclib/budget"
+	"aoclib/input"
+	"aoclib/versions"
 )
 
 func main() {
 	// program input
 	filename := flag.String("f", "", "input file name (required)")
-	version := flag.String("v", "1", "logic version")
+	version := flag.String("v", "1", "logic version, see -list")
 	connection := flag.Int("c", 10, "number of connections for the logic")
+	list := flag.Bool("list", false, "list available versions and exit")
+	maxStates := flag.Int("max-states", 0, "stop state searches after this many states, 0 for unlimited")
+	maxMem := flag.Uint64("max-mem", 0, "stop state searches past this many MiB of heap, 0 for unlimited")
 	flag.Parse()
 
-	// main logic
-	var result string
-	var err error
-	switch *version {
-	case "1":
-		if *connection < 1 {
-			fmt.Fprintf(os.Stderr, "Error: connection must be >= 1, got %d\n", *connection)
+	limits := budget.Limits{MaxStates: *maxStates, MaxBytes: *maxMem << 20}
+
+	// n is number of points, c the number of connections
+	versionTable := versions.Table{
+		{
+			Name:        "1",
+			Description: "part one, N shortest pairs from a bounded heap, circuits merged with maps",
+			Complexity:  "O(n^2 log c)",
+			Method:      versions.Exact,
+			Run: func(filename string) (string, error) {
+				if *connection < 1 {
+					return "", fmt.Errorf("connection must be >= 1, got %d", *connection)
+				}
+				return processV1(filename, *connection)
+			},
+		},
+		{
+			Name:        "1a",
+			Description: "part one, N shortest pairs from a bounded heap, circuits with disjoint set",
+			Complexity:  "O(n^2 log c)",
+			Method:      versions.Exact,
+			Run: func(filename string) (string, error) {
+				return processV1a(filename, *connection)
+			},
+		},
+		{
+			Name:        "2",
+			Description: "part two, sort all pairs then Kruskal until one circuit is left",
+			Complexity:  "O(n^2 log n)",
+			Method:      versions.Exact,
+			Run: func(filename string) (string, error) {
+				return processV2(filename, limits)
+			},
+		},
+	}
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		result, err = processV1(*filename, *connection)
-	case "1a":
-		result, err = processV1a(*filename, *connection)
-	case "2":
-		result, err = processV2(*filename)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown version %s\n", *version)
-		os.Exit(1)
+		return
 	}
 
+	// main logic
+	result, err := versionTable.Execute(*version, *filename)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -119,6 +152,9 @@ func processV1(filename string, connection int) (string, error) {
 		sizes = append(sizes, 1)
 	}
 
+	if len(sizes) < 3 {
+		return "", fmt.Errorf("only %d circuits left after %d connections, need 3", len(sizes), connection)
+	}
 	result := sizes[0] * sizes[1] * sizes[2]
 	return fmt.Sprintf("%d", result), nil
 }
@@ -155,19 +191,31 @@ func processV1a(filename string, connection int) (string, error) {
 		return sizes[i] > sizes[j]
 	})
 
+	if len(sizes) < 3 {
+		return "", fmt.Errorf("only %d circuits left after %d connections, need 3", len(sizes), connection)
+	}
 	result := sizes[0] * sizes[1] * sizes[2]
 	return fmt.Sprintf("%d", result), nil
 }
 
-func processV2(filename string) (string, error) {
+func processV2(filename string, limits budget.Limits) (string, error) {
 	// get points from file
 	points, err := readPointsFromFile(filename)
 	if err != nil {
 		return "", err
 	}
+	pairBudget := limits.New() // after reading, the points themselves are not what it caps
 
 	// since we need all pairs sorted, heap complexity won't help much here
-	pairs := make([]pair, 0, len(points)*(len(points)-1)/2)
+	// all n(n-1)/2 pairs are allocated at once, so check the budget before not after
+	numPairs := len(points) * (len(points) - 1) / 2
+	if err := pairBudget.Add(numPairs); err != nil {
+		return "", err
+	}
+	if err := pairBudget.Expect(uint64(numPairs) * uint64(unsafe.Sizeof(pair{}))); err != nil {
+		return "", err
+	}
+	pairs := make([]pair, 0, numPairs)
 	for i := 0; i < len(points); i++ {
 		for j := i + 1; j < len(points); j++ {
 			dist := calcDist(points[i], points[j])
@@ -213,29 +261,15 @@ type (
 )
 
 func readPointsFromFile(filename string) ([]point, error) {
-	// open file
-	file, err := os.Open(filename)
+	records, err := input.CSVInts(filename, 3)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close() // error ignored (file only for reading)
 
-	// read points
-	var x, y, z int
-	points := []point{}
-	scanner := bufio.NewScanner(file)
-	for i := 0; scanner.Scan(); i++ {
-		line := scanner.Text()
-		_, err := fmt.Sscanf(line, "%d,%d,%d", &x, &y, &z)
-		if err != nil {
-			return nil, err
-		}
-		points = append(points, point{id: i, x: x, y: y, z: z})
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	points := make([]point, len(records))
+	for i, r := range records {
+		points[i] = point{id: i, x: r[0], y: r[1], z: r[2]}
 	}
-
 	return points, nil
 }
 
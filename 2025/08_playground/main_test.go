@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoclib/input"
+)
+
+func FuzzReadPoints(f *testing.F) {
+	seed, err := os.ReadFile("test1")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Add([]byte(""))
+	f.Add([]byte("1,2\n"))
+	f.Add([]byte("1,2,3,4\n"))
+	f.Add([]byte("-1, 2 ,+3\r\n\n9223372036854775807,0,-9223372036854775808\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fname := filepath.Join(t.TempDir(), "input")
+		if err := os.WriteFile(fname, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		points, err := readPointsFromFile(fname)
+		if err != nil {
+			if !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("readPointsFromFile(%q) failed with %T, want a *input.ParseError: %v", data, err, err)
+			}
+			return
+		}
+		// the union-find indexes its parents by point id
+		for i, p := range points {
+			if p.id != i {
+				t.Fatalf("readPointsFromFile(%q): point %d has id %d", data, i, p.id)
+			}
+		}
+	})
+}
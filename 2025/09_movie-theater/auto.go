@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"aoclib/versions"
+)
+
+type inputStats struct {
+	tiles      int
+	rows, cols int // distinct y and x values
+	minX, maxX uint
+	minY, maxY uint
+}
+
+func inspectInput(filename string) (inputStats, error) {
+	tiles, err := readTiles(filename)
+	if err != nil {
+		return inputStats{}, err
+	}
+
+	rows := make(map[uint]struct{})
+	cols := make(map[uint]struct{})
+	stats := inputStats{tiles: len(tiles)}
+	stats.minX, stats.maxX = tiles[0].x, tiles[0].x
+	stats.minY, stats.maxY = tiles[0].y, tiles[0].y
+	for _, t := range tiles {
+		rows[t.y] = struct{}{}
+		cols[t.x] = struct{}{}
+		stats.minX, stats.maxX = min(stats.minX, t.x), max(stats.maxX, t.x)
+		stats.minY, stats.maxY = min(stats.minY, t.y), max(stats.maxY, t.y)
+	}
+	stats.rows, stats.cols = len(rows), len(cols)
+	return stats, nil
+}
+
+func (s inputStats) String() string {
+	return fmt.Sprintf("%d tiles on %d rows and %d columns, x in [%d,%d], y in [%d,%d]",
+		s.tiles, s.rows, s.cols, s.minX, s.maxX, s.minY, s.maxY)
+}
+
+func autoPolicy(p2 bool) versions.Policy {
+	return func(filename string) (string, string, error) {
+		stats, err := inspectInput(filename)
+		if err != nil {
+			return "", "", err
+		}
+
+		// part two: 2 only samples and 2a floods the whole bounding box,
+		// the edge based check is the only one that is both exact and fast
+		if p2 {
+			return "2b", fmt.Sprintf("%s, edge based check is exact at any size", stats), nil
+		}
+
+		// part one: 1a pairs rows (or columns) instead of tiles,
+		// it only pays off when tiles share rows or columns
+		lines := min(stats.rows, stats.cols)
+		if lines < stats.tiles {
+			return "1a", fmt.Sprintf("%s, pairing %d lines beats pairing %d tiles",
+				stats, lines, stats.tiles), nil
+		}
+		return "1", fmt.Sprintf("%s, no tiles share a line so plain pairing is simplest", stats), nil
+	}
+}
@@ -1,3 +1,7 @@
 module aoc
 
 go 1.25.5
+
+require aoclib v0.0.0
+
+replace aoclib => ../lib
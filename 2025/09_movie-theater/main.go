@@ -7,39 +7,89 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"aoclib/budget"
+	"aoclib/input"
+	"aoclib/intmath"
+	"aoclib/versions"
 )
 
 func main() {
 	// program input
 	filename := flag.String("f", "", "input file name (required)")
-	version := flag.String("v", "1", "logic version")
+	version := flag.String("v", "1", "logic version, see -list, or auto to pick from the input")
+	p2 := flag.Bool("p2", false, "with -v auto, pick among part two versions")
 	sampleSize := flag.Int("s", 0, "sample size for version 2")
+	list := flag.Bool("list", false, "list available versions and exit")
+	maxStates := flag.Int("max-states", 0, "stop state searches after this many states, 0 for unlimited")
+	maxMem := flag.Uint64("max-mem", 0, "stop state searches past this many MiB of heap, 0 for unlimited")
 	flag.Parse()
 
-	now := time.Now()
+	limits := budget.Limits{MaxStates: *maxStates, MaxBytes: *maxMem << 20}
 
-	// main logic
-	var result string
-	var err error
-	switch *version {
-	case "1":
-		result, err = processV1(*filename)
-	case "1a":
-		result, err = processV1a(*filename)
-	case "2":
-		if *sampleSize < 1 {
-			fmt.Fprintf(os.Stderr, "Error: sample size must be > 0\n")
+	// n is number of red tiles, R/C distinct rows/columns, W*H the bounding box, s the sample size
+	versionTable := versions.Table{
+		{
+			Name:        "1",
+			Description: "part one, check every pair of red tiles",
+			Complexity:  "O(n^2)",
+			Method:      versions.Exact,
+			Run:         processV1,
+		},
+		{
+			Name:        "1a",
+			Description: "part one, keep only row/column extremes then pair the rows (or columns)",
+			Complexity:  "O(n + min(R,C)^2)",
+			Method:      versions.Exact,
+			Run:         processV1a,
+		},
+		{
+			Name:        "2",
+			Description: "part two, tile-level ray casting, large rectangles only sampled (-s)",
+			Complexity:  "O(n^3 * s^2)",
+			Method:      versions.Sampling,
+			Run: func(filename string) (string, error) {
+				if *sampleSize < 1 {
+					return "", fmt.Errorf("sample size must be > 0")
+				}
+				return processV2(filename, *sampleSize)
+			},
+		},
+		{
+			Name:        "2a",
+			Description: "part two, flood fill the whole bounding box with BFS, too slow for real input",
+			Complexity:  "O(W*H + n^2 * W*H)",
+			Method:      versions.Exact,
+			Deprecated:  true,
+			Run: func(filename string) (string, error) {
+				return processV2a(filename, limits)
+			},
+		},
+		{
+			Name:        "2b",
+			Description: "part two, rectangle vs axis-aligned polygon edges, no inner tiles touched",
+			Complexity:  "O(n^3)",
+			Method:      versions.Exact,
+			Run:         processV2b,
+		},
+	}
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		result, err = processV2(*filename, *sampleSize)
-	case "2a":
-		result, err = processV2a(*filename)
-	case "2b":
-		result, err = processV2b(*filename)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown version %s\n", *version)
+		return
+	}
+
+	now := time.Now()
+
+	// main logic
+	name, err := versionTable.Select(*version, *filename, autoPolicy(*p2))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	result, err := versionTable.Execute(name, *filename)
 
 	diff := time.Since(now)
 	fmt.Printf("Time taken: %v\n", diff)
@@ -55,31 +105,72 @@ type tile struct {
 	x, y uint
 }
 
+// readTiles reads the red tiles in input order, one `x,y` per line.
+// Part one only pairs them up, so any tiles will do.
+func readTiles(filename string) ([]tile, error) {
+	tiles, _, err := readTileLines(filename)
+	return tiles, err
+}
+
+// readLoop reads the red tiles like readTiles, for part two they must also form a closed loop:
+// each tile shares a row or column with the one before, and the last with the first.
+// The part two versions walk the edges between consecutive tiles and rely on that.
+func readLoop(filename string) ([]tile, error) {
+	tiles, lines, err := readTileLines(filename)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(tiles); i++ {
+		if !inLine(tiles[i-1], tiles[i]) {
+			return nil, lines[i].Errorf(0, "tile shares no row or column with the previous tile %d,%d", tiles[i-1].x, tiles[i-1].y)
+		}
+	}
+	if last := len(tiles) - 1; !inLine(tiles[last], tiles[0]) {
+		return nil, lines[last].Errorf(0, "tile shares no row or column with the first tile %d,%d, the loop must close", tiles[0].x, tiles[0].y)
+	}
+	return tiles, nil
+}
+
+func readTileLines(filename string) ([]tile, []input.Line, error) {
+	lines, err := input.Lines(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil, &input.ParseError{File: filename, Msg: "no red tiles in input"}
+	}
+	tiles := make([]tile, len(lines))
+	for i, line := range lines {
+		if tiles[i], err = parseTile(line.Text); err != nil {
+			return nil, nil, line.Locate(err)
+		}
+	}
+	return tiles, lines, nil
+}
+
+func inLine(a, b tile) bool {
+	return a.x == b.x || a.y == b.y
+}
+
 func parseTile(s string) (tile, error) {
+	at := input.Line{Text: s}
 	sep := strings.IndexByte(s, ',')
 	if sep == -1 {
-		return tile{}, fmt.Errorf("invalid tile format: %s", s)
+		return tile{}, at.Errorf(0, "invalid tile format, expected x,y")
 	}
 	x, err := strconv.ParseUint(s[:sep], 10, 32)
 	if err != nil {
-		return tile{}, err
+		return tile{}, at.Errorf(1, "invalid x %q: %w", s[:sep], err)
 	}
 	y, err := strconv.ParseUint(s[sep+1:], 10, 32)
 	if err != nil {
-		return tile{}, err
+		return tile{}, at.Errorf(sep+2, "invalid y %q: %w", s[sep+1:], err)
 	}
 	return tile{uint(x), uint(y)}, nil
 }
 
 func calcArea(t1, t2 tile) uint {
-	x := absDiff(t1.x, t2.x) + 1
-	y := absDiff(t1.y, t2.y) + 1
+	x := intmath.AbsDiff(t1.x, t2.x) + 1
+	y := intmath.AbsDiff(t1.y, t2.y) + 1
 	return x * y
 }
-
-func absDiff(a, b uint) uint {
-	if a > b {
-		return a - b
-	}
-	return b - a
-}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoclib/input"
+)
+
+func FuzzParseTile(f *testing.F) {
+	lines, err := input.Lines("test1")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, l := range lines {
+		f.Add(l.Text)
+	}
+	f.Add("")
+	f.Add(",")
+	f.Add("4294967295,4294967295")
+	f.Add("4294967296,0")
+	f.Add("-1,2")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		tl, err := parseTile(s)
+		if err != nil {
+			if !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("parseTile(%q) failed with %T, want a *input.ParseError: %v", s, err, err)
+			}
+			return
+		}
+		// whatever was accepted reads back as the same tile, and fits the 32 bits the areas assume
+		back, err := parseTile(fmt.Sprintf("%d,%d", tl.x, tl.y))
+		if err != nil || back != tl {
+			t.Fatalf("parseTile(%q) = %v, which reads back as %v, %v", s, tl, back, err)
+		}
+		if tl.x > 1<<32-1 || tl.y > 1<<32-1 {
+			t.Fatalf("parseTile(%q) = %v, past 32 bits", s, tl)
+		}
+	})
+}
+
+func TestReadLoop(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		fname := filepath.Join(dir, name)
+		if err := os.WriteFile(fname, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return fname
+	}
+	scattered := write("scattered", "1,1\n5,7\n3,2\n")
+	open := write("open", "1,1\n1,5\n4,5\n")
+
+	// part one pairs tiles up, it doesn't care how they are ordered
+	if got, err := processV1(scattered); err != nil || got != "Largest rectangle area: 35" {
+		t.Errorf("processV1 on scattered tiles = %q, %v", got, err)
+	}
+	if got, err := processV1a(scattered); err != nil || got != "Largest rectangle area: 35" {
+		t.Errorf("processV1a on scattered tiles = %q, %v", got, err)
+	}
+
+	// part two walks the edges, so a broken or open loop is an error on the line that breaks it
+	for _, tc := range []struct {
+		fname string
+		line  int
+	}{{scattered, 2}, {open, 3}} {
+		_, err := readLoop(tc.fname)
+		var pe *input.ParseError
+		if !errors.As(err, &pe) || pe.Line != tc.line {
+			t.Errorf("readLoop(%s) = %v, want a *input.ParseError on line %d", filepath.Base(tc.fname), err, tc.line)
+		}
+		if _, err := processV2b(tc.fname); err == nil {
+			t.Errorf("processV2b(%s) accepted tiles that are no loop", filepath.Base(tc.fname))
+		}
+	}
+	if _, err := readLoop("test1"); err != nil {
+		t.Errorf("readLoop(test1) = %v", err)
+	}
+}
@@ -1,33 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+
+	"aoclib/intmath"
 )
 
 func processV1(filename string) (string, error) {
-	// open file
-	file, err := os.Open(filename)
+	tiles, err := readTiles(filename)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close() // error ignored (file only for reading)
-
-	// read file line by line
-	tiles := []tile{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		tile, err := parseTile(line)
-		if err != nil {
-			return "", err
-		}
-		tiles = append(tiles, tile)
-	}
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
 
 	// check largest area
 	largestArea := uint(0)
@@ -43,12 +26,10 @@ func processV1(filename string) (string, error) {
 }
 
 func processV1a(filename string) (string, error) {
-	// open file
-	file, err := os.Open(filename)
+	tiles, err := readTiles(filename)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close() // error ignored (file only for reading)
 
 	// key idea: working row-by-row or column-by-column
 	// for a fixed pair of rows/columns, we only need the leftmost and rightmost (topmost and bottommost) tiles
@@ -61,15 +42,7 @@ func processV1a(filename string) (string, error) {
 	rowMap := make(map[uint]mm)
 	colMap := make(map[uint]mm)
 
-	// read file line by line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		tile, err := parseTile(line)
-		if err != nil {
-			return "", err
-		}
-
+	for _, tile := range tiles {
 		// check for row (y)
 		if r, ok := rowMap[tile.y]; !ok {
 			rowMap[tile.y] = mm{tile.x, tile.x} // first entry
@@ -96,9 +69,6 @@ func processV1a(filename string) (string, error) {
 			colMap[tile.x] = c
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
 
 	// check which is smaller (for efficiency)
 	toCheck := rowMap
@@ -136,10 +106,10 @@ func processV1a(filename string) (string, error) {
 			sj := slices[j]
 
 			// slice distance
-			ds := absDiff(si.n, sj.n) + 1
+			ds := intmath.AbsDiff(si.n, sj.n) + 1
 			// max min distance
-			d1 := absDiff(si.max, sj.min) + 1
-			d2 := absDiff(sj.max, si.min) + 1
+			d1 := intmath.AbsDiff(si.max, sj.min) + 1
+			d2 := intmath.AbsDiff(sj.max, si.min) + 1
 			dm := max(d1, d2)
 
 			area := ds * dm
@@ -1,18 +1,8 @@
 package main
 
-import (
-	"bufio"
-	"fmt"
-	"os"
-)
+import "fmt"
 
 func processV2(filename string, sampleSize int) (string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
 	// idea: ray casting -> i'll admit i'm asking youtube for this :'(
 	// https://www.youtube.com/watch?v=RyLuE5xFLxw
 	// - first get the red tiles (they form a closed loop in order)
@@ -21,17 +11,8 @@ func processV2(filename string, sampleSize int) (string, error) {
 	// - to optimize large sparse rectangles, use sampling instead of checking every tile
 
 	// read red tiles, they form a loop (next is always adjacent to previous)
-	polygonCorners := []tile{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		t, err := parseTile(line)
-		if err != nil {
-			return "", err
-		}
-		polygonCorners = append(polygonCorners, t)
-	}
-	if err := scanner.Err(); err != nil {
+	polygonCorners, err := readLoop(filename)
+	if err != nil {
 		return "", err
 	}
 
@@ -1,19 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"unsafe"
+
+	"aoclib/budget"
 )
 
-func processV2a(filename string) (string, error) {
-	// open file
-	file, err := os.Open(filename)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close() // error ignored (file only for reading)
+// tileEntryBytes is a lower bound on what one more tile costs in a map[tile]bool
+const tileEntryBytes = uint64(unsafe.Sizeof(tile{}) + 1)
 
+func processV2a(filename string, limits budget.Limits) (string, error) {
 	// the idea:
 	// - manually build where are the red and green tiles
 	//   note that the input red tiles are in order, i.e.,
@@ -24,28 +21,28 @@ func processV2a(filename string) (string, error) {
 	//   whehter it belongs to green tiles or red tiles
 
 	// get all red tiles
-	redTiles := []tile{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		tile, err := parseTile(line)
-		if err != nil {
-			return "", err
-		}
-		redTiles = append(redTiles, tile)
-	}
-	if err := scanner.Err(); err != nil {
+	redTiles, err := readLoop(filename)
+	if err != nil {
 		return "", err
 	}
 
+	// both fills share one budget, it starts after reading so the input doesn't count
+	fill := limits.New()
+
 	isRedTile := make(map[tile]bool)
 	for _, t := range redTiles {
 		isRedTile[t] = true
 	}
 
 	// get all green tiles
-	isGreenTileBoundary := getGreenTilesBoundary(redTiles)
-	isGreenTileInterior := getGreenTilesInterior(redTiles, isGreenTileBoundary)
+	isGreenTileBoundary, err := getGreenTilesBoundary(redTiles, fill)
+	if err != nil {
+		return "", err
+	}
+	isGreenTileInterior, err := getGreenTilesInterior(redTiles, isGreenTileBoundary, fill)
+	if err != nil {
+		return "", err
+	}
 	isGreenTile := make(map[tile]bool)
 	for t := range isGreenTileBoundary {
 		isGreenTile[t] = true
@@ -88,7 +85,7 @@ func isValidRectangle(t1, t2 tile, isRedTile, isGreenTile map[tile]bool) bool {
 	return true
 }
 
-func getGreenTilesBoundary(redTiles []tile) map[tile]bool {
+func getGreenTilesBoundary(redTiles []tile, limits *budget.Budget) (map[tile]bool, error) {
 	isGreenTile := make(map[tile]bool)
 
 	// connect consecutive red tiles
@@ -101,20 +98,35 @@ func getGreenTilesBoundary(redTiles []tile) map[tile]bool {
 		// add all tiles between t1 and t2 (exclusive)
 		if t1.x == t2.x { // same column, fill vertically
 			minY, maxY := min(t1.y, t2.y), max(t1.y, t2.y)
+			if err := reserveTiles(limits, maxY-minY+1); err != nil {
+				return nil, err
+			}
 			for y := minY; y <= maxY; y++ {
 				isGreenTile[tile{t1.x, y}] = true
 			}
 		} else if t1.y == t2.y { // same row, fill horizontally
 			minX, maxX := min(t1.x, t2.x), max(t1.x, t2.x)
+			if err := reserveTiles(limits, maxX-minX+1); err != nil {
+				return nil, err
+			}
 			for x := minX; x <= maxX; x++ {
 				isGreenTile[tile{x, t1.y}] = true
 			}
 		}
 	}
-	return isGreenTile
+	return isGreenTile, nil
 }
 
-func getGreenTilesInterior(redTiles []tile, isGreenTileBoundary map[tile]bool) map[tile]bool {
+// reserveTiles counts n tiles about to go into a map, before they are put there:
+// one segment of a long edge alone can be billions of tiles
+func reserveTiles(limits *budget.Budget, n uint) error {
+	if err := limits.Add(int(min(n, 1<<62))); err != nil {
+		return err
+	}
+	return limits.Expect(uint64(n) * tileEntryBytes)
+}
+
+func getGreenTilesInterior(redTiles []tile, isGreenTileBoundary map[tile]bool, limits *budget.Budget) (map[tile]bool, error) {
 	// Before flood fill (? is padding)
 	// ????????????????
 	// ?..............?
@@ -151,15 +163,18 @@ func getGreenTilesInterior(redTiles []tile, isGreenTileBoundary map[tile]bool) m
 		maxY = max(maxY, t.y)
 	}
 
-	// add padding of 1 each side so we can flood fill from outside
-	minX--
-	maxX++
-	minY--
-	maxY++
+	// add padding of 1 each side so we can flood fill from outside,
+	// in signed coordinates as the padding goes below zero for tiles on x=0 or y=0
+	type cell struct{ x, y int }
+	x0, x1 := int(minX)-1, int(maxX)+1
+	y0, y1 := int(minY)-1, int(maxY)+1
+	isWall := func(c cell) bool {
+		return c.x >= 0 && c.y >= 0 && isGreenTileBoundary[tile{uint(c.x), uint(c.y)}]
+	}
 
 	// bfs flood fill to mark all exterior tiles
-	isExteriorTile := make(map[tile]bool)
-	queue := []tile{{minX, minY}}
+	isExteriorTile := make(map[cell]bool)
+	queue := []cell{{x0, y0}}
 	isExteriorTile[queue[0]] = true // this is true
 	i := 0
 	for len(queue) > 0 {
@@ -168,24 +183,27 @@ func getGreenTilesInterior(redTiles []tile, isGreenTileBoundary map[tile]bool) m
 		fmt.Printf("%d Visiting exterior tile %v\n", i, current)
 		i++
 
-		neighbors := []tile{
+		neighbors := []cell{
 			{current.x + 1, current.y},
 			{current.x - 1, current.y},
 			{current.x, current.y + 1},
 			{current.x, current.y - 1},
 		}
 		for _, next := range neighbors {
-			if next.x < minX || next.x > maxX || next.y < minY || next.y > maxY {
+			if next.x < x0 || next.x > x1 || next.y < y0 || next.y > y1 {
 				continue // out of bounds
 			}
 			if isExteriorTile[next] {
 				continue // already marked
 			}
-			if isGreenTileBoundary[next] {
+			if isWall(next) {
 				continue // can't cross the green wall
 			}
 			isExteriorTile[next] = true
 			queue = append(queue, next)
+			if err := limits.Add(1); err != nil {
+				return nil, err // bounding box too large to flood fill
+			}
 		}
 	}
 
@@ -194,10 +212,13 @@ func getGreenTilesInterior(redTiles []tile, isGreenTileBoundary map[tile]bool) m
 	for x := minX; x <= maxX; x++ {
 		for y := minY; y <= maxY; y++ {
 			t := tile{x, y}
-			if !isExteriorTile[t] && !isGreenTileBoundary[t] {
+			if !isExteriorTile[cell{int(x), int(y)}] && !isGreenTileBoundary[t] {
 				isGreenTileInterior[t] = true
+				if err := limits.Add(1); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
-	return isGreenTileInterior
+	return isGreenTileInterior, nil
 }
@@ -1,10 +1,6 @@
 package main
 
-import (
-	"bufio"
-	"fmt"
-	"os"
-)
+import "fmt"
 
 type line struct {
 	p1, p2 tile
@@ -22,7 +18,7 @@ func processV2b(filename string) (string, error) {
 	// - here, we exploit the fact that the polygon is axis-aligned (only vertical/horizontal edges),
 	//   due to that, we can just check based on endpoints/corners, no need the inner points
 
-	corners, err := getCorners(filename)
+	corners, err := readLoop(filename)
 	if err != nil {
 		return "", err
 	}
@@ -47,29 +43,6 @@ func processV2b(filename string) (string, error) {
 	return fmt.Sprintf("Largest rectangle area: %d", maxArea), nil
 }
 
-func getCorners(filename string) ([]tile, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	corners := []tile{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		t, err := parseTile(line)
-		if err != nil {
-			return nil, err
-		}
-		corners = append(corners, t)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	return corners, nil
-}
-
 func buildEdges(corners []tile) ([]line, error) {
 	n := len(corners)
 	edges := make([]line, n)
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"aoclib/versions"
+)
+
+// bruteForceMaxButtons is where processV1 stops being practical,
+// 2^20 subsets per machine is about the most we want to simulate
+const bruteForceMaxButtons = 20
+
+type inputStats struct {
+	machines   int
+	maxButtons int // most buttons on a single machine
+	maxLights  int // most lights (= counters) on a single machine
+	maxJoltage int // largest joltage target
+}
+
+func inspectInput(filename string) (inputStats, error) {
+	machines, err := readFile(filename)
+	if err != nil {
+		return inputStats{}, err
+	}
+
+	stats := inputStats{machines: len(machines)}
+	for _, m := range machines {
+		stats.maxButtons = max(stats.maxButtons, len(m.buttons))
+		stats.maxLights = max(stats.maxLights, len(m.lightsReq))
+		for _, j := range m.joltageReq {
+			stats.maxJoltage = max(stats.maxJoltage, j)
+		}
+	}
+	return stats, nil
+}
+
+func (s inputStats) String() string {
+	return fmt.Sprintf("%d machines, up to %d buttons, %d lights, joltage %d",
+		s.machines, s.maxButtons, s.maxLights, s.maxJoltage)
+}
+
+func autoPolicy(p2 bool) versions.Policy {
+	return func(filename string) (string, string, error) {
+		stats, err := inspectInput(filename)
+		if err != nil {
+			return "", "", err
+		}
+
+		// part two: BFS (v2) is deprecated, the ILP solver is the only practical one
+		if p2 {
+			return "2a", fmt.Sprintf("%s, only ILP scales with joltage", stats), nil
+		}
+
+		// part one: brute force is simpler and fast enough for few buttons,
+		// past that the 2^b subsets explode and Gaussian elimination wins
+		if stats.maxButtons <= bruteForceMaxButtons {
+			return "1", fmt.Sprintf("%s, at most %d buttons is cheap to brute force",
+				stats, bruteForceMaxButtons), nil
+		}
+		return "1a", fmt.Sprintf("%s, more than %d buttons needs Gaussian elimination",
+			stats, bruteForceMaxButtons), nil
+	}
+}
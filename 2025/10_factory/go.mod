@@ -2,4 +2,9 @@ module aoc
 
 go 1.25.5
 
-require github.com/draffensperger/golp v0.0.0-20250721104811-2d405f0b4e68
+require (
+	aoclib v0.0.0
+	github.com/draffensperger/golp v0.0.0-20250721104811-2d405f0b4e68
+)
+
+replace aoclib => ../lib
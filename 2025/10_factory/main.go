@@ -1,39 +1,85 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
+
+	"aoclib/budget"
+	"aoclib/input"
+	"aoclib/parse"
+	"aoclib/versions"
 )
 
 func main() {
 	// program input
 	filename := flag.String("f", "", "input file name (required)")
-	version := flag.String("v", "1", "logic version")
+	version := flag.String("v", "1", "logic version, see -list, or auto to pick from the input")
+	p2 := flag.Bool("p2", false, "with -v auto, pick among part two versions")
+	list := flag.Bool("list", false, "list available versions and exit")
+	maxStates := flag.Int("max-states", 0, "stop a machine's state search after this many states, 0 for unlimited")
+	maxMem := flag.Uint64("max-mem", 0, "stop a machine's state search past this many MiB of heap, 0 for unlimited")
+	ckptPath := flag.String("checkpoint", "", "file to save per-machine results to and resume from")
 	flag.Parse()
 
+	limits := budget.Limits{MaxStates: *maxStates, MaxBytes: *maxMem << 20}
+
+	// b is number of buttons, L number of lights/counters, f number of free variables,
+	// and B the largest joltage target
+	versionTable := versions.Table{
+		{
+			Name:        "1",
+			Description: "part one, brute force every subset of buttons",
+			Complexity:  "O(2^b * b*L)",
+			Method:      versions.Exact,
+			Run:         processV1,
+		},
+		{
+			Name:        "1a",
+			Description: "part one, Gaussian elimination in GF(2) then enumerate free variables",
+			Complexity:  "O(b^2*L + 2^f * b*L)",
+			Method:      versions.Exact,
+			Run:         processV1a,
+		},
+		{
+			Name:        "2",
+			Description: "part two, BFS in counter space, impractical for real input",
+			Complexity:  "O(B^L)",
+			Method:      versions.Exact,
+			Deprecated:  true,
+			Run: func(filename string) (string, error) {
+				return processV2(filename, limits, *ckptPath)
+			},
+		},
+		{
+			Name:        "2a",
+			Description: "part two, integer linear programming with lp_solve (golp)",
+			Complexity:  "exponential worst case (branch and bound)",
+			Method:      versions.Exact,
+			Run: func(filename string) (string, error) {
+				return processV2a(filename, *ckptPath)
+			},
+		},
+	}
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	now := time.Now()
 
 	// main logic
-	var result string
-	var err error
-	switch *version {
-	case "1":
-		result, err = processV1(*filename)
-	case "1a":
-		result, err = processV1a(*filename)
-	case "2":
-		result, err = processV2(*filename)
-	case "2a":
-		result, err = processV2a(*filename)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown version %s\n", *version)
+	name, err := versionTable.Select(*version, *filename, autoPolicy(*p2))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	result, err := versionTable.Execute(name, *filename)
 
 	diff := time.Since(now)
 	fmt.Printf("Time taken: %v\n", diff)
@@ -64,101 +110,73 @@ func lightsEqual(a, b []bool) bool {
 }
 
 func readFile(filename string) ([]machine, error) {
-	// open file
-	file, err := os.Open(filename)
+	lines, err := input.Lines(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close() // error handling omitted for brevity
-
-	// read line by line
-	machines := []machine{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		machine, err := parseLine(line)
+
+	machines := make([]machine, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue // blank lines, trailing ones included, hold no machine
+		}
+		m, err := parseLine(line)
 		if err != nil {
 			return nil, err
 		}
-		machines = append(machines, machine)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		machines = append(machines, m)
 	}
-
 	return machines, nil
 }
 
-func parseLine(line string) (machine, error) {
+// lights, buttons and joltage of a machine line
+var (
+	lightsParser  = parse.Delimited("[", "", "]", parse.OneOf(".#"))
+	buttonsParser = parse.Many(parse.WithPos(parse.Delimited("(", ",", ")", parse.Uint)))
+	joltageParser = parse.Delimited("{", ",", "}", parse.Uint)
+)
+
+func parseLine(line input.Line) (machine, error) {
 	// we assume line is under the format:
 	// `[.##.] (3) (1,3) (2) (2,3) (0,2) (0,1) {3,5,4,7}`
 	// lightsConfig is under square brackets []
 	// buttons are all under parentheses
 	// joltage are under curly braces {}
-
-	// get lights configuration
-	lights := []bool{}
-	leftBracket := strings.Index(line, "[")
-	rightBracket := strings.Index(line, "]")
-	if leftBracket == -1 || rightBracket == -1 || rightBracket < leftBracket {
-		return machine{}, fmt.Errorf("invalid lights configuration")
-	}
-	for c := leftBracket + 1; c < rightBracket; c++ {
-		switch line[c] {
-		case '#':
-			lights = append(lights, true)
-		case '.':
-			lights = append(lights, false)
-		default:
-			return machine{}, fmt.Errorf("invalid character in lights configuration: %c", line[c])
+	return parse.Parse(line, func(s *parse.Scanner) (machine, error) {
+		cells, err := lightsParser(s)
+		if err != nil {
+			return machine{}, err
+		}
+		lights := make([]bool, len(cells))
+		for i, c := range cells {
+			lights[i] = c == '#'
 		}
-	}
 
-	// get joltage configuration
-	joltage := []int{}
-	leftBrace := strings.Index(line, "{")
-	rightBrace := strings.Index(line, "}")
-	if leftBrace == -1 || rightBrace == -1 || rightBrace < leftBrace {
-		return machine{}, fmt.Errorf("invalid joltage configuration")
-	}
-	for numStr := range strings.SplitSeq(line[leftBrace+1:rightBrace], ",") {
-		num, err := strconv.Atoi(strings.TrimSpace(numStr))
+		// every button must only toggle lights that exist, the solvers index lights by them
+		located, err := buttonsParser(s)
 		if err != nil {
-			return machine{}, fmt.Errorf("invalid joltage number: %s", numStr)
+			return machine{}, err
 		}
-		joltage = append(joltage, num)
-	}
-
-	// assert lights and joltage lengths match
-	if len(lights) != len(joltage) {
-		return machine{}, fmt.Errorf("mismatched lights and joltage lengths")
-	}
-
-	// get buttons configuration
-	buttons := make([][]int, 0)
-	for btnStr := range strings.FieldsSeq(line[rightBracket+1 : leftBrace]) {
-		trimmed := strings.Trim(btnStr, "()")
-		splitted := strings.Split(trimmed, ",")
-		if len(splitted) == 1 {
-			btnNum, err := strconv.Atoi(splitted[0])
-			if err != nil {
-				return machine{}, fmt.Errorf("invalid button number: %s", splitted[0])
-			}
-			buttons = append(buttons, []int{btnNum})
-		} else if len(splitted) > 1 {
-			innerBtns := []int{}
-			for _, numStr := range splitted {
-				btnNum, err := strconv.Atoi(numStr)
-				if err != nil {
-					return machine{}, fmt.Errorf("invalid button number: %s", numStr)
+		buttons := make([][]int, len(located))
+		for i, button := range located {
+			for _, light := range button.Val {
+				if light >= len(lights) {
+					return machine{}, s.Errorf(button.Col, "button toggles light %d, but there are only %d lights", light, len(lights))
 				}
-				innerBtns = append(innerBtns, btnNum)
 			}
-			buttons = append(buttons, innerBtns)
-		} else {
-			return machine{}, fmt.Errorf("invalid button configuration: %s", btnStr)
+			buttons[i] = button.Val
 		}
-	}
 
-	return machine{lights, joltage, buttons}, nil
+		joltageCol := s.Col()
+		joltage, err := joltageParser(s)
+		if err != nil {
+			return machine{}, err
+		}
+
+		// assert lights and joltage lengths match
+		if len(lights) != len(joltage) {
+			return machine{}, s.Errorf(joltageCol, "mismatched lights and joltage lengths: %d lights, %d joltages", len(lights), len(joltage))
+		}
+		return machine{lights, joltage, buttons}, nil
+	})
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"aoclib/input"
+)
+
+func FuzzParseLine(f *testing.F) {
+	lines, err := input.Lines("test1")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, l := range lines {
+		f.Add(l.Text)
+	}
+	f.Add("")
+	f.Add("[] {}")
+	f.Add("[.#] (2) {1,1}")
+	f.Add("[.#] (0,1) {1}")
+	f.Add("[.#](0)(1){1,2}")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		m, err := parseLine(input.Line{File: "fuzz", Num: 1, Text: text})
+		if err != nil {
+			if !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("parseLine(%q) failed with %T, want a *input.ParseError: %v", text, err, err)
+			}
+			return
+		}
+		if len(m.lightsReq) != len(m.joltageReq) {
+			t.Fatalf("parseLine(%q) has %d lights but %d joltages", text, len(m.lightsReq), len(m.joltageReq))
+		}
+
+		// the solvers index lights by button numbers, pressing everything touches them all
+		all := make([]int, len(m.buttons))
+		for i := range all {
+			all[i] = i
+		}
+		m.simulatePresses(all)
+		if len(m.buttons) <= 12 { // the GF(2) solve enumerates free variables, keep it quick
+			matrix := m.buildAugmentedMatrix()
+			freeVars := m.gaussianElimination(matrix)
+			if m.isConsistent(matrix) {
+				m.findMinButtonPresses(matrix, freeVars)
+			}
+		}
+	})
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"aoclib/budget"
+	"aoclib/checkpoint"
 )
 
-func processV2(filename string) (string, error) {
+func processV2(filename string, limits budget.Limits, ckptPath string) (string, error) {
 	// approach brainstorming 1:
 	// - we can see that, similar to our v1a, we can approach this problem using linear equations, and solve the augmented matrix Ax=b just like before
 	// - but since we now are using natural numbers and not just booleans that we can do XOR with, we have more complexity
@@ -36,12 +39,32 @@ func processV2(filename string) (string, error) {
 		return "", err
 	}
 
+	// every machine is independent, so finished ones are saved and skipped on rerun
+	ckpt, err := checkpoint.Open(ckptPath, "10/2", filename)
+	if err != nil {
+		return "", err
+	}
+	defer ckpt.Close() // results are synced on every Put already
+
 	totalPresses := 0
 	for i, m := range machines {
-		presses := m.solveBFS()
+		if presses, ok := ckpt.Get(i); ok {
+			fmt.Printf("Machine %d: total presses = %d (checkpoint)\n", i, presses)
+			totalPresses += presses
+			continue
+		}
+		// a fresh budget per machine: the limits cap one search, and the states in
+		// an error are this machine's, not a running total over the ones before
+		presses, err := m.solveBFS(limits.New())
+		if err != nil {
+			return "", fmt.Errorf("machine %d: %w", i, err)
+		}
 		if presses < 0 {
 			return "", fmt.Errorf("no solution found for machine %d", i)
 		}
+		if err := ckpt.Put(i, presses); err != nil {
+			return "", err
+		}
 		fmt.Printf("Machine %d: total presses = %d\n", i, presses)
 		totalPresses += presses
 	}
@@ -49,7 +72,7 @@ func processV2(filename string) (string, error) {
 	return fmt.Sprintf("Total button presses for all machines: %d", totalPresses), nil
 }
 
-func (m *machine) solveBFS() int {
+func (m *machine) solveBFS(limits *budget.Budget) (int, error) {
 	numButtons, numCounters := len(m.buttons), len(m.joltageReq)
 
 	// convert each button into a fixed-length effect array of size numCounters
@@ -80,7 +103,7 @@ func (m *machine) solveBFS() int {
 
 		// if we've reached the goal, return distance directly, this is shortest by BFS logic
 		if isArrayEqual(curr.state, goalState) {
-			return curr.dist
+			return curr.dist, nil
 		}
 
 		// otherwise, expand neighbors by pressing each button once
@@ -108,12 +131,15 @@ func (m *machine) solveBFS() int {
 				continue
 			}
 			visited[nextKey] = true
+			if err := limits.Add(1); err != nil {
+				return -1, err // state space too large, give up before we run out of memory
+			}
 
 			queue = append(queue, node{state: nextState, dist: curr.dist + 1})
 		}
 	}
 
-	return -1 // no solution found
+	return -1, nil // no solution found
 }
 
 func encodeState(state []int) string {
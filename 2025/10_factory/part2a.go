@@ -5,21 +5,37 @@ import (
 	"fmt"
 	"slices"
 
+	"aoclib/checkpoint"
 	"github.com/draffensperger/golp"
 )
 
-func processV2a(filename string) (string, error) {
+func processV2a(filename string, ckptPath string) (string, error) {
 	machines, err := readFile(filename)
 	if err != nil {
 		return "", err
 	}
 
+	// same as v2, finished machines are saved and skipped on rerun
+	ckpt, err := checkpoint.Open(ckptPath, "10/2a", filename)
+	if err != nil {
+		return "", err
+	}
+	defer ckpt.Close() // results are synced on every Put already
+
 	totalPresses := 0
 	for i, m := range machines {
+		if presses, ok := ckpt.Get(i); ok {
+			fmt.Printf("Machine %d: total presses = %d (checkpoint)\n", i, presses)
+			totalPresses += presses
+			continue
+		}
 		presses, solution := m.solveWithGOLP()
 		if presses < 0 {
 			return "", fmt.Errorf("no solution found for machine %d", i)
 		}
+		if err := ckpt.Put(i, presses); err != nil {
+			return "", err
+		}
 		fmt.Printf("Machine %d: total presses = %d, solution = %v\n", i, presses, solution)
 		totalPresses += presses
 	}
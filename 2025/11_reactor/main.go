@@ -1,34 +1,36 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
-	"regexp"
+	"strings"
 	"time"
+
+	"aoclib/input"
+	"aoclib/parse"
+	"aoclib/versions"
 )
 
 func main() {
 	// program input
 	filename := flag.String("f", "", "input file name (required)")
-	version := flag.String("v", "1", "logic version")
+	version := flag.String("v", "1", "logic version, see -list")
+	list := flag.Bool("list", false, "list available versions and exit")
 	flag.Parse()
 
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	now := time.Now()
 
 	// main logic
-	var result string
-	var err error
-	switch *version {
-	case "1":
-		result, err = processV1(*filename)
-	case "2":
-		result, err = processV2(*filename)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown version %s\n", *version)
-		os.Exit(1)
-	}
+	result, err := versionTable.Execute(*version, *filename)
 
 	diff := time.Since(now)
 	fmt.Printf("Time taken: %v\n", diff)
@@ -40,45 +42,62 @@ func main() {
 	fmt.Println(result)
 }
 
-type connections map[string][]string
+// V is number of devices, E number of connections
+var versionTable = versions.Table{
+	{
+		Name:        "1",
+		Description: "part one, memoized DFS counting paths from you to out",
+		Complexity:  "O(V+E)",
+		Method:      versions.Exact,
+		Run:         processV1,
+	},
+	{
+		Name:        "2",
+		Description: "part two, memoized DFS from svr to out tracking visits to dac and fft",
+		Complexity:  "O(4*(V+E))",
+		Method:      versions.Exact,
+		Run:         processV2,
+	},
+}
 
-var (
-	linePattern   = regexp.MustCompile(`^([a-z]{3}):\s*(.*)$`)
-	devicePattern = regexp.MustCompile(`[a-z]{3}`)
-)
+type connections map[string][]string
 
 func readFile(filename string) (connections, error) {
-	// open file
-	file, err := os.Open(filename)
+	lines, err := input.Lines(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close() // error handling omitted for brevity
 
-	// read line by line
 	connections := make(connections)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue // blank lines, trailing ones included, hold no device
+		}
 		from, to, err := parseLine(line)
 		if err != nil {
 			return nil, err
 		}
 		connections[from] = to
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
 	return connections, nil
 }
 
-func parseLine(line string) (string, []string, error) {
-	matches := linePattern.FindStringSubmatch(line)
-	if matches == nil || len(matches) != 3 {
-		return "", nil, fmt.Errorf("invalid line format")
+// parseLine parses `from: to to ...`, devices are identifiers of any length
+func parseLine(line input.Line) (string, []string, error) {
+	type entry struct {
+		from string
+		to   []string
 	}
-	from := matches[1]
-	to := devicePattern.FindAllString(matches[2], -1)
-	return from, to, nil
+	e, err := parse.Parse(line, func(s *parse.Scanner) (entry, error) {
+		from, err := parse.Ident(s)
+		if err != nil {
+			return entry{}, err
+		}
+		if _, err := parse.Token(":")(s); err != nil {
+			return entry{}, err
+		}
+		to, err := parse.Many(parse.Ident)(s)
+		return entry{from, to}, err
+	})
+	return e.from, e.to, err
 }
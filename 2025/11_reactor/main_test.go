@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"aoclib/input"
+)
+
+func FuzzParseLine(f *testing.F) {
+	for _, name := range []string{"test1", "test2"} {
+		lines, err := input.Lines(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, l := range lines {
+			f.Add(l.Text)
+		}
+	}
+	f.Add("")
+	f.Add(":")
+	f.Add("you:")
+	f.Add("a_1:b-2  c3\t")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		from, to, err := parseLine(input.Line{File: "fuzz", Num: 1, Text: text})
+		if err != nil {
+			if !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("parseLine(%q) failed with %T, want a *input.ParseError: %v", text, err, err)
+			}
+			return
+		}
+		// device names are map keys later on, an empty one would be a device nobody can name
+		if from == "" || slices.Contains(to, "") {
+			t.Fatalf("parseLine(%q) = %q -> %q, with an empty device", text, from, to)
+		}
+	})
+}
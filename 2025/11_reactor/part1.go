@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"aoclib/checked"
+)
 
 func processV1(filename string) (string, error) {
 	deviceMap, err := readFile(filename)
@@ -18,21 +22,21 @@ func processV1(filename string) (string, error) {
 	start := "you"
 	target := "out"
 	visited := make(map[string]bool)
-	memo := make(map[string]int) // memo[c] = computed number of paths from c to target
+	memo := make(map[string]checked.Int) // memo[c] = computed number of paths from c to target, can pass 64 bits
 
-	var dfs func(from string) int
-	dfs = func(from string) int {
+	var dfs func(from string) checked.Int
+	dfs = func(from string) checked.Int {
 		if from == target {
-			return 1
+			return checked.NewInt(1)
 		} // base case, found 1 path
 		if visited[from] {
 			return memo[from] // already computed in current path, avoid cycle implicitly
 		}
 
 		visited[from] = true
-		var total int
+		var total checked.Int
 		for _, to := range deviceMap[from] { // try all outgoing paths
-			total += dfs(to)
+			total = total.Add(dfs(to))
 		}
 
 		memo[from] = total
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"aoclib/checked"
+)
 
 func processV2(filename string) (string, error) {
 	connections, err := readFile(filename)
@@ -12,15 +16,19 @@ func processV2(filename string) (string, error) {
 
 	// use state for memoization: 0 = neither, 1 = dac only, 2 = fft only, 3 = both
 	// memo[device][state] = num of paths from device to "out" with that state
-	memo := make(map[string]map[int]int)
+	memo := make(map[string]map[int]checked.Int)
+
+	// unlike v1 we don't skip revisits, a cycle would recurse forever, so report it instead
+	onPath := make(map[string]bool)
+	cycleAt := ""
 
-	var dfs func(string, int) int
-	dfs = func(from string, state int) int {
+	var dfs func(string, int) checked.Int
+	dfs = func(from string, state int) checked.Int {
 		if from == "out" {
 			if state == 3 {
-				return 1
+				return checked.NewInt(1)
 			}
-			return 0
+			return checked.Int{}
 		} // base case
 
 		if memo[from] != nil {
@@ -28,6 +36,12 @@ func processV2(filename string) (string, error) {
 				return count // memoization check
 			}
 		}
+		if onPath[from] {
+			cycleAt = from
+			return checked.Int{}
+		}
+		onPath[from] = true
+		defer delete(onPath, from)
 
 		// update state based on current device
 		newState := state
@@ -40,17 +54,21 @@ func processV2(filename string) (string, error) {
 			newState |= 2
 		}
 
-		var total int
+		var total checked.Int
 		for _, to := range connections[from] {
-			total += dfs(to, newState) // try all outgoing paths
+			total = total.Add(dfs(to, newState)) // try all outgoing paths
 		}
 
 		if memo[from] == nil {
-			memo[from] = make(map[int]int)
+			memo[from] = make(map[int]checked.Int)
 		}
 		memo[from][state] = total
 		return total
 	}
 
-	return fmt.Sprintf("Total possible path is %d", dfs("svr", 0)), nil
+	total := dfs("svr", 0)
+	if cycleAt != "" {
+		return "", fmt.Errorf("device %s is part of a cycle, paths through it cannot be counted", cycleAt)
+	}
+	return fmt.Sprintf("Total possible path is %d", total), nil
 }
@@ -1,34 +1,49 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
+
+	"aoclib/input"
+	"aoclib/parse"
+	"aoclib/versions"
 )
 
 func main() {
 	// program input
 	filename := flag.String("f", "", "input file name (required)")
-	version := flag.String("v", "1", "logic version")
+	version := flag.String("v", "1", "logic version, see -list")
+	list := flag.Bool("list", false, "list available versions and exit")
+	ckptPath := flag.String("checkpoint", "", "file to save per-region results to and resume from")
 	flag.Parse()
 
+	// s is number of shape cells, r number of regions, p number of present kinds
+	versionTable := versions.Table{
+		{
+			Name:        "1",
+			Description: "part one, area check with an eyeballed 1.3 slack factor, no actual packing",
+			Complexity:  "O(s + r*p)",
+			Method:      versions.Heuristic,
+			Run: func(filename string) (string, error) {
+				return processV1(filename, *ckptPath)
+			},
+		},
+	}
+
+	if *list {
+		if err := versionTable.Fprint(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	now := time.Now()
 
 	// main logic
-	var result string
-	var err error
-	switch *version {
-	case "1":
-		result, err = processV1(*filename)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown version %s\n", *version)
-		os.Exit(1)
-	}
+	result, err := versionTable.Execute(*version, *filename)
 
 	diff := time.Since(now)
 	fmt.Printf("Time taken: %v\n", diff)
@@ -52,109 +67,126 @@ type (
 	}
 )
 
+// headers that tell the two sections apart: "4:" starts a shape, "12x5: ..." a region
 var (
-	regionPattern = regexp.MustCompile(`^(\d+)x(\d+):\s*(.*)$`)
-	posNumPattern = regexp.MustCompile(`\d+`)
+	shapeHeader = func(s *parse.Scanner) (int, error) {
+		id, err := parse.Uint(s)
+		if err != nil {
+			return 0, err
+		}
+		_, err = parse.Token(":")(s)
+		return id, err
+	}
+	regionHeader = func(s *parse.Scanner) ([2]int, error) {
+		width, err := parse.Uint(s)
+		if err != nil {
+			return [2]int{}, err
+		}
+		if _, err := parse.Token("x")(s); err != nil {
+			return [2]int{}, err
+		}
+		height, err := parse.Uint(s)
+		if err != nil {
+			return [2]int{}, err
+		}
+		_, err = parse.Token(":")(s)
+		return [2]int{width, height}, err
+	}
+	shapeRow = parse.Many(parse.OneOf("#."))
 )
 
 func readInput(filename string) (*aoc, error) {
-	// open file
-	file, err := os.Open(filename)
+	// one block per shape, then the regions, each starting at its header line.
+	// blank lines between them are only decoration, they may also be missing or sit inside a shape
+	lines, err := input.Lines(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	blocks := parse.Blocks(lines, func(l input.Line) bool {
+		return parse.Matches(l, shapeHeader) || parse.Matches(l, regionHeader)
+	})
 
 	result := &aoc{
 		presents: make(map[int]*shape),
 		regions:  []*region{},
 	}
-
-	// we read the file in two sections: shapes and regions
-	// since we read line by line, we need to track the current shape being read
-	var curShapeBuf [][]int
-	curShapeID := -1
-	inShapes := true
-	flushShape := func() {
-		if curShapeID >= 0 {
-			s := shape(curShapeBuf)
-			result.presents[curShapeID] = &s
-		}
-		curShapeID = -1
-		curShapeBuf = nil // we initialize it when we read a new shape
-	}
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue // skip empty line
-		}
-
-		// if region section detected, switch mode
-		if inShapes && strings.Contains(line, "x") && strings.Contains(line, ":") {
-			inShapes = false
-			flushShape()
-		}
-
-		if inShapes {
-			// shape header: "id:"
-			if strings.HasSuffix(line, ":") {
-				flushShape()
-
-				idStr := strings.TrimSuffix(line, ":")
-				id, err := strconv.Atoi(idStr)
+	err = parse.Sections(blocks,
+		parse.Section{
+			Name:   "shape",
+			Header: func(first input.Line) bool { return parse.Matches(first, shapeHeader) },
+			Parse: func(block []input.Line) error {
+				id, s, err := parseShape(block)
 				if err != nil {
-					return nil, err
+					return err
 				}
-				curShapeID = id
-				curShapeBuf = [][]int{} // reset buffer
-				continue
-			}
-
-			// shape row
-			row := make([]int, len(line))
-			for i, ch := range line {
-				if ch == '#' {
-					row[i] = 1
-				} else {
-					row[i] = 0
+				if _, dup := result.presents[id]; dup {
+					return block[0].Errorf(1, "shape %d is defined twice", id)
 				}
-			}
-			curShapeBuf = append(curShapeBuf, row)
-
-		} else {
-			// at this point, we are in region section
-			matches := regionPattern.FindStringSubmatch(line)
-			if matches == nil || len(matches) != 4 {
-				return nil, fmt.Errorf("invalid region line: %q", line)
-			}
+				result.presents[id] = s
+				return nil
+			},
+		},
+		parse.Section{
+			Name:   "regions",
+			Header: func(first input.Line) bool { return parse.Matches(first, regionHeader) },
+			Parse: func(block []input.Line) error {
+				for _, at := range block {
+					r, err := parseRegion(at)
+					if err != nil {
+						return err
+					}
+					for id, count := range r.presentsCount {
+						if _, ok := result.presents[id]; count > 0 && !ok {
+							return at.Errorf(0, "region needs %d presents of shape %d, which is not defined", count, id)
+						}
+					}
+					result.regions = append(result.regions, r)
+				}
+				return nil
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-			width, err := strconv.Atoi(matches[1])
-			if err != nil {
-				return nil, err
-			}
-			height, err := strconv.Atoi(matches[2])
-			if err != nil {
-				return nil, err
-			}
+// parseShape parses a shape block: a header "id:" followed by rows of '#' and '.'
+func parseShape(block []input.Line) (int, *shape, error) {
+	id, err := parse.Parse(block[0], shapeHeader)
+	if err != nil {
+		return 0, nil, err
+	}
 
-			countStrs := posNumPattern.FindAllString(matches[3], -1)
-			presentsCount := make([]int, len(countStrs))
-			for i, cs := range countStrs {
-				v, err := strconv.Atoi(cs)
-				if err != nil {
-					return nil, err
-				}
-				presentsCount[i] = v
+	s := make(shape, 0, len(block)-1)
+	for _, at := range block[1:] {
+		cells, err := parse.Parse(at, shapeRow)
+		if err != nil {
+			return 0, nil, err
+		}
+		row := make([]int, len(cells))
+		for i, c := range cells {
+			if c == '#' {
+				row[i] = 1
 			}
-
-			result.regions = append(result.regions, &region{width, height, presentsCount})
 		}
+		s = append(s, row)
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
+	return id, &s, nil
+}
 
-	return result, nil
+// parseRegion parses a region line `WxH: n n ...`
+func parseRegion(at input.Line) (*region, error) {
+	return parse.Parse(at, func(s *parse.Scanner) (*region, error) {
+		size, err := regionHeader(s)
+		if err != nil {
+			return nil, err
+		}
+		presentsCount, err := parse.Many(parse.Uint)(s)
+		if err != nil {
+			return nil, err
+		}
+		return &region{size[0], size[1], presentsCount}, nil
+	})
 }
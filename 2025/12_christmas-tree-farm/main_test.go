@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoclib/input"
+)
+
+func FuzzReadInput(f *testing.F) {
+	seed, err := os.ReadFile("test1")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Add([]byte(""))
+	f.Add([]byte("0:\n\n#\n\n1x1: 1\n"))
+	f.Add([]byte("4x4: 0 0\n"))
+	f.Add([]byte("0:\n#.\n0:\n.#\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fname := filepath.Join(t.TempDir(), "input")
+		if err := os.WriteFile(fname, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		result, err := readInput(fname)
+		if err != nil {
+			if !errors.As(err, new(*input.ParseError)) {
+				t.Fatalf("readInput(%q) failed with %T, want a *input.ParseError: %v", data, err, err)
+			}
+			return
+		}
+		// part one sizes regions and looks up every shape they need
+		for _, r := range result.regions {
+			for id, count := range r.presentsCount {
+				if _, ok := result.presents[id]; count > 0 && !ok {
+					t.Fatalf("readInput(%q) accepted a region needing undefined shape %d", data, id)
+				}
+			}
+		}
+	})
+}
@@ -1,13 +1,24 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
 
-func processV1(filename string) (string, error) {
+	"aoclib/checkpoint"
+)
+
+func processV1(filename string, ckptPath string) (string, error) {
 	aoc, err := readInput(filename)
 	if err != nil {
 		return "", err
 	}
 
+	// regions are independent, so finished ones are saved and skipped on rerun
+	ckpt, err := checkpoint.Open(ckptPath, "12/1", filename)
+	if err != nil {
+		return "", err
+	}
+	defer ckpt.Close() // results are synced on every Put already
+
 	// log on googling:
 	// - gamedev stackoverflow -> packing problem wiki rabbithole
 	// - awesome blog: https://www.gorillasun.de/blog/a-simple-solution-for-shape-packing-in-2d/
@@ -52,7 +63,12 @@ func processV1(filename string) (string, error) {
 
 	// count area in each region and the presents area
 	result := 0
-	for _, region := range aoc.regions {
+	for i, region := range aoc.regions {
+		if fits, ok := ckpt.Get(i); ok {
+			result += fits
+			continue
+		}
+
 		area := region.width * region.height
 		presentsArea := 0
 		for presentID, count := range region.presentsCount {
@@ -60,14 +76,19 @@ func processV1(filename string) (string, error) {
 		}
 
 		// this 1.3 factor is just eyeballing, it is incorrect on test1 but input is correct somehow
+		fits := 0
 		if float64(presentsArea)*1.3 < float64(area) {
 			fmt.Printf("Correct? %dx%d: area=%d, presentsArea=%d\n", region.width, region.height, area, presentsArea)
-			result++
+			fits = 1
 		} else if presentsArea > area {
 			fmt.Printf("Invalid %dx%d: area=%d, presentsArea=%d\n", region.width, region.height, area, presentsArea)
 		} else {
 			fmt.Printf("Correct??!! %dx%d: area=%d, presentsArea=%d\n", region.width, region.height, area, presentsArea)
 		}
+		if err := ckpt.Put(i, fits); err != nil {
+			return "", err
+		}
+		result += fits
 	}
 	return fmt.Sprintf("Supposedly correct regions: %d", result), nil
 }
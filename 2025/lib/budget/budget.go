@@ -0,0 +1,154 @@
+// Package budget caps how many states and how much memory a search may use.
+//
+// Some solvers (BFS over counter states, flood fills over huge bounding boxes,
+// materialising every pair of points) can grow until the process is OOM-killed.
+// They count their states into a Budget instead, and stop with an ExceededError
+// as soon as one of the limits is hit.
+//
+// A nil *Budget is valid and never runs out, so solvers can take one unconditionally.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"runtime/metrics"
+)
+
+// checkEvery is how many states pass between two memory reads,
+// reading the runtime metrics is cheap but not free
+const checkEvery = 1024
+
+// ErrExceeded is matched by every ExceededError through errors.Is.
+var ErrExceeded = errors.New("budget exceeded")
+
+// ExceededError reports which limit ran out and how far the search got.
+type ExceededError struct {
+	States int    // states counted so far
+	Bytes  uint64 // estimated heap bytes the search uses (or was about to use)
+	Limit  string // the limit that was hit, human readable
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("budget exceeded after %d states (~%s in use): %s", e.States, formatBytes(e.Bytes), e.Limit)
+}
+
+func (e *ExceededError) Unwrap() error {
+	return ErrExceeded
+}
+
+// Budget tracks the states and memory used by a search.
+type Budget struct {
+	maxStates int    // 0 means unlimited
+	maxBytes  uint64 // 0 means unlimited
+	states    int
+	sinceRead int    // states counted since last memory read
+	baseline  uint64 // heap bytes in use when the budget was created
+	samples   []metrics.Sample
+}
+
+// Limits are the caps of a budget, kept apart from one so that every search can start its own:
+// a budget counts states from zero and memory from when it is created.
+type Limits struct {
+	MaxStates int    // 0 means unlimited
+	MaxBytes  uint64 // 0 means unlimited
+}
+
+// New creates a budget with these limits, create it right before the search so the input
+// it was read from doesn't count against MaxBytes.
+func (l Limits) New() *Budget {
+	return New(l.MaxStates, l.MaxBytes)
+}
+
+// New creates a budget; a zero limit means that resource is unlimited.
+// If both limits are zero, New returns nil (the unlimited budget).
+func New(maxStates int, maxBytes uint64) *Budget {
+	if maxStates <= 0 && maxBytes == 0 {
+		return nil
+	}
+	b := &Budget{
+		maxStates: max(maxStates, 0),
+		maxBytes:  maxBytes,
+		samples: []metrics.Sample{
+			{Name: "/gc/heap/allocs:bytes"},
+			{Name: "/gc/heap/frees:bytes"},
+		},
+	}
+	b.baseline = b.heapInUse()
+	return b
+}
+
+// Add counts n new states and checks the limits.
+// Memory is only read every few thousand states, so it may overshoot a little.
+func (b *Budget) Add(n int) error {
+	if b == nil {
+		return nil
+	}
+	b.states += n
+	if b.maxStates > 0 && b.states > b.maxStates {
+		return b.exceeded(fmt.Sprintf("max %d states", b.maxStates), b.used())
+	}
+	b.sinceRead += n
+	if b.sinceRead >= checkEvery {
+		b.sinceRead = 0
+		return b.checkBytes(0)
+	}
+	return nil
+}
+
+// Expect checks that an allocation of about n more bytes still fits,
+// call it before one big allocation that Add would only notice afterwards.
+func (b *Budget) Expect(n uint64) error {
+	if b == nil {
+		return nil
+	}
+	return b.checkBytes(n)
+}
+
+// States returns how many states have been counted so far.
+func (b *Budget) States() int {
+	if b == nil {
+		return 0
+	}
+	return b.states
+}
+
+func (b *Budget) checkBytes(extra uint64) error {
+	if b.maxBytes == 0 {
+		return nil
+	}
+	if used := b.used() + extra; used > b.maxBytes {
+		return b.exceeded(fmt.Sprintf("max %s of memory", formatBytes(b.maxBytes)), used)
+	}
+	return nil
+}
+
+func (b *Budget) exceeded(limit string, bytes uint64) error {
+	return &ExceededError{States: b.states, Bytes: bytes, Limit: limit}
+}
+
+// used estimates the heap bytes the search holds: allocations minus frees since New
+func (b *Budget) used() uint64 {
+	inUse := b.heapInUse()
+	if inUse < b.baseline {
+		return 0 // something from before the search got freed
+	}
+	return inUse - b.baseline
+}
+
+func (b *Budget) heapInUse() uint64 {
+	metrics.Read(b.samples)
+	return b.samples[0].Value.Uint64() - b.samples[1].Value.Uint64()
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,79 @@
+package budget
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestAddStates(t *testing.T) {
+	b := New(10, 0)
+	for i := range 10 {
+		if err := b.Add(1); err != nil {
+			t.Fatalf("Add #%d = %v, want nil up to the limit", i+1, err)
+		}
+	}
+	err := b.Add(1)
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("Add past 10 states = %v, want ErrExceeded", err)
+	}
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.States != 11 {
+		t.Fatalf("Add past 10 states = %#v, want an ExceededError at 11 states", err)
+	}
+
+	// one big Add trips as well, before anything was done with it
+	if err := New(10, 0).Add(1 << 40); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("Add(1<<40) = %v, want ErrExceeded", err)
+	}
+}
+
+func TestExpect(t *testing.T) {
+	b := New(0, 1<<20)
+	if err := b.Expect(1 << 10); err != nil {
+		t.Fatalf("Expect(1KiB) under 1MiB = %v", err)
+	}
+	if err := b.Expect(2 << 20); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("Expect(2MiB) under 1MiB = %v, want ErrExceeded", err)
+	}
+	if b.States() != 0 {
+		t.Fatalf("Expect counted %d states", b.States())
+	}
+}
+
+func TestAddMemory(t *testing.T) {
+	b := New(0, 1<<20)
+	held := make([][]byte, 0, 64)
+	var err error
+	for i := 0; i < 64 && err == nil; i++ {
+		held = append(held, make([]byte, 1<<20))
+		err = b.Add(checkEvery) // every call reads the memory
+	}
+	runtime.KeepAlive(held)
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("Add while holding %d MiB under a 1MiB limit = %v, want ErrExceeded", len(held), err)
+	}
+	if len(held) > 2 {
+		t.Fatalf("Add only tripped after %d MiB under a 1MiB limit", len(held))
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	if b := New(0, 0); b != nil {
+		t.Fatalf("New(0, 0) = %v, want nil", b)
+	}
+	if b := (Limits{}).New(); b != nil {
+		t.Fatalf("Limits{}.New() = %v, want nil", b)
+	}
+
+	var b *Budget
+	if err := b.Add(1 << 40); err != nil {
+		t.Fatalf("nil Add = %v", err)
+	}
+	if err := b.Expect(1 << 60); err != nil {
+		t.Fatalf("nil Expect = %v", err)
+	}
+	if b.States() != 0 {
+		t.Fatalf("nil States = %d", b.States())
+	}
+}
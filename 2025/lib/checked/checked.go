@@ -0,0 +1,169 @@
+// Package checked does integer arithmetic that never silently wraps around.
+//
+// Add, Sub and Mul are the plain int operations plus an overflow flag, for hot loops
+// that only need to know. Int builds on them: it is a machine int until an operation
+// overflows, then it redoes that operation with math/big and stays big from there on.
+// Answers that fit in an int cost no allocations, larger ones come out exact.
+package checked
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Add returns a+b, ok is false if it overflowed.
+func Add(a, b int) (int, bool) {
+	r := a + b
+	if (b > 0 && r < a) || (b < 0 && r > a) {
+		return r, false
+	}
+	return r, true
+}
+
+// Sub returns a-b, ok is false if it overflowed.
+func Sub(a, b int) (int, bool) {
+	r := a - b
+	if (b > 0 && r > a) || (b < 0 && r < a) {
+		return r, false
+	}
+	return r, true
+}
+
+// Mul returns a*b, ok is false if it overflowed.
+func Mul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	r := a * b
+	if r/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return r, false
+	}
+	return r, true
+}
+
+// Int is an integer that switches to math/big on overflow, the zero value is 0.
+type Int struct {
+	small int
+	big   *big.Int // non-nil once the value left the int range, never mutated after creation
+}
+
+// NewInt returns v as an Int.
+func NewInt(v int) Int {
+	return Int{small: v}
+}
+
+// FromUint64 returns v as an Int, values above math.MaxInt start out big.
+func FromUint64(v uint64) Int {
+	if v > math.MaxInt {
+		return Int{big: new(big.Int).SetUint64(v)}
+	}
+	return Int{small: int(v)}
+}
+
+// FromBig returns a copy of b as an Int, small if it fits in an int.
+func FromBig(b *big.Int) Int {
+	return fromBig(new(big.Int).Set(b))
+}
+
+// Parse reads a decimal integer of any size.
+func Parse(s string) (Int, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		return Int{small: v}, nil
+	} else if err.(*strconv.NumError).Err != strconv.ErrRange {
+		return Int{}, err
+	}
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Int{}, fmt.Errorf("invalid integer %q", s)
+	}
+	return Int{big: b}, nil
+}
+
+// Add returns x+y.
+func (x Int) Add(y Int) Int {
+	if x.big == nil && y.big == nil {
+		if r, ok := Add(x.small, y.small); ok {
+			return Int{small: r}
+		}
+	}
+	return fromBig(new(big.Int).Add(x.Big(), y.Big()))
+}
+
+// Sub returns x-y.
+func (x Int) Sub(y Int) Int {
+	if x.big == nil && y.big == nil {
+		if r, ok := Sub(x.small, y.small); ok {
+			return Int{small: r}
+		}
+	}
+	return fromBig(new(big.Int).Sub(x.Big(), y.Big()))
+}
+
+// Mul returns x*y.
+func (x Int) Mul(y Int) Int {
+	if x.big == nil && y.big == nil {
+		if r, ok := Mul(x.small, y.small); ok {
+			return Int{small: r}
+		}
+	}
+	return fromBig(new(big.Int).Mul(x.Big(), y.Big()))
+}
+
+// Cmp compares x and y and returns -1, 0 or +1.
+func (x Int) Cmp(y Int) int {
+	if x.big == nil && y.big == nil {
+		switch {
+		case x.small < y.small:
+			return -1
+		case x.small > y.small:
+			return 1
+		}
+		return 0
+	}
+	return x.Big().Cmp(y.Big())
+}
+
+// Int returns x as an int, ok is false if it does not fit.
+func (x Int) Int() (int, bool) {
+	return x.small, x.big == nil
+}
+
+// IsBig reports whether x needed math/big.
+func (x Int) IsBig() bool {
+	return x.big != nil
+}
+
+// Big returns x as a new *big.Int.
+func (x Int) Big() *big.Int {
+	if x.big != nil {
+		return new(big.Int).Set(x.big)
+	}
+	return big.NewInt(int64(x.small))
+}
+
+func (x Int) String() string {
+	if x.big != nil {
+		return x.big.String()
+	}
+	return strconv.Itoa(x.small)
+}
+
+// Format makes Int work with the integer verbs of fmt, like %d and %x.
+func (x Int) Format(f fmt.State, verb rune) {
+	x.Big().Format(f, verb)
+}
+
+// MarshalJSON writes x as a plain JSON number, whatever its size, like big.Int does.
+func (x Int) MarshalJSON() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// fromBig shrinks a result back to an int when it fits again (e.g. after a subtraction)
+func fromBig(b *big.Int) Int {
+	if b.IsInt64() && b.Int64() >= math.MinInt && b.Int64() <= math.MaxInt {
+		return Int{small: int(b.Int64())}
+	}
+	return Int{big: b}
+}
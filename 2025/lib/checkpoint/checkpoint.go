@@ -0,0 +1,197 @@
+// Package checkpoint saves per-instance results of long runs so they can be resumed.
+//
+// Solvers that loop over many independent instances (machines, regions, ...) Put each
+// result as soon as it is known. The file is append-only JSON lines: a header with the
+// solver name and the SHA-256 of the input, then one line per finished instance.
+// Opening the same file for the same solver and input loads those results back,
+// a checkpoint of another input or solver, or no file yet, starts from scratch.
+// Any other existing file is an error: a mistyped path must not eat the puzzle input.
+//
+// A nil *Checkpoint is valid and simply remembers nothing.
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+type header struct {
+	Solver string `json:"solver"`
+	Input  string `json:"input"` // hex SHA-256 of the input file
+}
+
+type entry struct {
+	Index int `json:"i"`
+	Value int `json:"v"`
+}
+
+// Checkpoint holds the results loaded from, and appended to, a checkpoint file.
+type Checkpoint struct {
+	file    *os.File
+	results map[int]int
+}
+
+// Open loads the checkpoint at path for the given solver and input file.
+// An empty path disables checkpointing and returns nil.
+func Open(path, solver, inputFile string) (*Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	sum, err := hashFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	want := header{Solver: solver, Input: sum}
+
+	c := &Checkpoint{results: make(map[int]int)}
+	ok, err := c.load(path, want)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+		}
+		return c, nil
+	}
+
+	// stale or missing checkpoint, start over with a fresh header
+	c.results = make(map[int]int)
+	c.file, err = os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	if err := c.writeLine(want); err != nil {
+		c.file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// load reads an existing checkpoint, it reports false when there is none for this run
+// and fails on an existing file that is no checkpoint at all, rather than have Open wipe it.
+// A torn last line (crash in the middle of a write) is cut off so appends stay parseable.
+func (c *Checkpoint) load(path string, want header) (bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close() // error ignored (file only for reading)
+
+	reader := bufio.NewReader(file)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if len(line) == 0 {
+		return false, nil // empty file, nothing to lose
+	}
+	// only ever overwrite a checkpoint, of another solver or input
+	got, ok := parseHeader(line)
+	if !ok {
+		return false, fmt.Errorf("%s exists and is not a checkpoint file, refusing to overwrite it", path)
+	}
+	if errors.Is(err, io.EOF) || got != want {
+		return false, nil // stale, or nothing but a header, start over
+	}
+
+	good := int64(len(line)) // offset right after the last complete line
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break // a partial line without newline is torn, ignore it
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read checkpoint: %w", err)
+		}
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			break // garbage, keep everything before it
+		}
+		c.results[e.Index] = e.Value
+		good += int64(len(line))
+	}
+	if err := os.Truncate(path, good); err != nil {
+		return false, fmt.Errorf("failed to repair checkpoint: %w", err)
+	}
+	return true, nil
+}
+
+// parseHeader reads a header line, ok is false for anything a checkpoint would not have written
+func parseHeader(line []byte) (header, bool) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	var h header
+	if err := dec.Decode(&h); err != nil || dec.More() {
+		return header{}, false
+	}
+	if sum, err := hex.DecodeString(h.Input); h.Solver == "" || err != nil || len(sum) != sha256.Size {
+		return header{}, false
+	}
+	return h, true
+}
+
+// Get returns the saved result of instance i, if any.
+func (c *Checkpoint) Get(i int) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+	v, ok := c.results[i]
+	return v, ok
+}
+
+// Put records the result of instance i and flushes it to disk right away.
+func (c *Checkpoint) Put(i, v int) error {
+	if c == nil {
+		return nil
+	}
+	c.results[i] = v
+	if err := c.writeLine(entry{Index: i, Value: v}); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+// Close closes the checkpoint file, the results stay on disk for the next run.
+func (c *Checkpoint) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+func (c *Checkpoint) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func hashFile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close() // error ignored (file only for reading)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash input: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
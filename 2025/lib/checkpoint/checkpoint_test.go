@@ -0,0 +1,178 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setup writes an input file and returns it with a checkpoint path next to it
+func setup(t *testing.T, content string) (input, ckpt string) {
+	t.Helper()
+	dir := t.TempDir()
+	input = filepath.Join(dir, "input")
+	if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return input, filepath.Join(dir, "ckpt")
+}
+
+func open(t *testing.T, path, solver, input string) *Checkpoint {
+	t.Helper()
+	c, err := Open(path, solver, input)
+	if err != nil {
+		t.Fatalf("Open(%s) = %v", path, err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func put(t *testing.T, c *Checkpoint, results map[int]int) {
+	t.Helper()
+	for i, v := range results {
+		if err := c.Put(i, v); err != nil {
+			t.Fatalf("Put(%d, %d) = %v", i, v, err)
+		}
+	}
+}
+
+// expect checks c holds exactly want among instances 0..9
+func expect(t *testing.T, c *Checkpoint, want map[int]int) {
+	t.Helper()
+	for i := range 10 {
+		v, ok := c.Get(i)
+		if w, wok := want[i]; ok != wok || v != w {
+			t.Errorf("Get(%d) = %d, %v, want %d, %v", i, v, ok, w, wok)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	input, path := setup(t, "1,2,3\n")
+	c := open(t, path, "10/2", input)
+	put(t, c, map[int]int{0: 7, 3: 42})
+	c.Close()
+
+	c = open(t, path, "10/2", input)
+	expect(t, c, map[int]int{0: 7, 3: 42})
+	put(t, c, map[int]int{5: 1})
+	c.Close()
+
+	expect(t, open(t, path, "10/2", input), map[int]int{0: 7, 3: 42, 5: 1})
+}
+
+func TestStaleHeader(t *testing.T) {
+	input, path := setup(t, "1,2,3\n")
+	c := open(t, path, "10/2", input)
+	put(t, c, map[int]int{0: 7})
+	c.Close()
+
+	// another solver on the same file starts over
+	c = open(t, path, "12/1", input)
+	expect(t, c, nil)
+	put(t, c, map[int]int{1: 2})
+	c.Close()
+	expect(t, open(t, path, "12/1", input), map[int]int{1: 2})
+
+	// and so does the same solver once the input changed
+	if err := os.WriteFile(input, []byte("4,5,6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, open(t, path, "12/1", input), nil)
+}
+
+func TestForeignFile(t *testing.T) {
+	input, _ := setup(t, "1,2,3\n")
+	for name, content := range map[string]string{
+		"notes":           "my notes\nmore notes\n",
+		"one line":        "11-22,95-115",
+		"other json":      `{"solver":"10/2"}` + "\n",
+		"extra field":     `{"solver":"10/2","input":"` + strings.Repeat("0", 64) + `","x":1}` + "\n",
+		"bad hash":        `{"solver":"10/2","input":"abc"}` + "\n",
+		"entries only":    `{"i":0,"v":7}` + "\n",
+		"torn header":     `{"solver":"10/2","inp`,
+		"header and more": `{"solver":"10/2","input":"` + strings.Repeat("0", 64) + `"} trailing` + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if c, err := Open(path, "10/2", input); err == nil {
+				c.Close()
+				t.Fatalf("Open on a file that is no checkpoint succeeded")
+			}
+			if got, _ := os.ReadFile(path); string(got) != content {
+				t.Fatalf("file changed to %q", got)
+			}
+		})
+	}
+
+	// pointing the checkpoint at the input itself must not destroy it either
+	if _, err := Open(input, "10/2", input); err == nil {
+		t.Fatalf("Open with the input as checkpoint succeeded")
+	}
+	if got, _ := os.ReadFile(input); string(got) != "1,2,3\n" {
+		t.Fatalf("input changed to %q", got)
+	}
+}
+
+func TestEmptyFile(t *testing.T) {
+	input, path := setup(t, "1,2,3\n")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := open(t, path, "10/2", input)
+	put(t, c, map[int]int{2: 3})
+	c.Close()
+	expect(t, open(t, path, "10/2", input), map[int]int{2: 3})
+}
+
+func TestTornLastLine(t *testing.T) {
+	input, path := setup(t, "1,2,3\n")
+	c := open(t, path, "10/2", input)
+	put(t, c, map[int]int{0: 7, 1: 8})
+	c.Close()
+
+	// a crash in the middle of the next Put
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"i":2,"v`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	c = open(t, path, "10/2", input)
+	expect(t, c, map[int]int{0: 7, 1: 8})
+	put(t, c, map[int]int{2: 9})
+	c.Close()
+
+	// the torn bytes are gone, so the new line parses instead of gluing onto them
+	expect(t, open(t, path, "10/2", input), map[int]int{0: 7, 1: 8, 2: 9})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 4 {
+		t.Fatalf("checkpoint has %d lines, want a header and 3 entries:\n%s", len(lines), data)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	c, err := Open("", "10/2", "does-not-matter")
+	if c != nil || err != nil {
+		t.Fatalf(`Open("") = %v, %v, want nil, nil`, c, err)
+	}
+	if err := c.Put(0, 1); err != nil {
+		t.Fatalf("Put on nil = %v", err)
+	}
+	if _, ok := c.Get(0); ok {
+		t.Fatalf("Get on nil found a result")
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close on nil = %v", err)
+	}
+}
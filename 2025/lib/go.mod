@@ -0,0 +1,3 @@
+module aoclib
+
+go 1.25.5
@@ -0,0 +1,128 @@
+// Package input holds what every day needs to read its puzzle input.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError points at the place in the input that could not be parsed.
+//
+// Its message looks like a compiler error, followed by the offending line
+// and a caret under the column, for example:
+//
+//	test1:3:1: invalid direction 'X'
+//	    X68
+//	    ^
+type ParseError struct {
+	File string // input file name, empty if unknown
+	Line int    // 1-based line number, 0 if unknown
+	Col  int    // 1-based byte column, 0 if the whole line is at fault
+	Text string // the offending line
+	Msg  string
+	Err  error // underlying cause (e.g. from strconv), may be nil
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	if e.File != "" {
+		sb.WriteString(e.File)
+		sb.WriteByte(':')
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "%d:", e.Line)
+		if e.Col > 0 {
+			fmt.Fprintf(&sb, "%d:", e.Col)
+		}
+	}
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(e.Msg)
+	if snippet := e.Snippet(); snippet != "" {
+		sb.WriteByte('\n')
+		sb.WriteString(snippet)
+	}
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Snippet returns the offending line with a caret under the column,
+// both indented by four spaces. It is empty when there is no text.
+func (e *ParseError) Snippet() string {
+	if e.Text == "" {
+		return ""
+	}
+	const indent = "    "
+	text := e.Text
+	if len(text) > 120 { // keep the snippet readable on very long lines
+		start := max(0, min(e.Col-60, len(text)-120))
+		text = text[start : start+120]
+		if e.Col > 0 {
+			return indent + text + "\n" + indent + caretPad(text, e.Col-start) + "^"
+		}
+	}
+	if e.Col <= 0 {
+		return indent + text
+	}
+	return indent + text + "\n" + indent + caretPad(text, e.Col) + "^"
+}
+
+// caretPad builds the padding before the caret,
+// tabs are kept so the caret lines up however wide the terminal renders them
+func caretPad(text string, col int) string {
+	var sb strings.Builder
+	for i := 0; i < col-1; i++ {
+		if i < len(text) && text[i] == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	return sb.String()
+}
+
+// Line is a line of input together with where it came from.
+type Line struct {
+	File string
+	Num  int // 1-based
+	Text string
+}
+
+// Errorf builds a ParseError at column col of this line, %w is supported for the cause.
+func (l Line) Errorf(col int, format string, args ...any) *ParseError {
+	err := fmt.Errorf(format, args...)
+	return &ParseError{
+		File: l.File,
+		Line: l.Num,
+		Col:  col,
+		Text: l.Text,
+		Msg:  err.Error(),
+		Err:  errors.Unwrap(err),
+	}
+}
+
+// Locate fills in the file and line number of a ParseError returned by a parser
+// that only saw the text of this line. Any other error becomes a ParseError for the whole line.
+func (l Line) Locate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		located := *pe
+		if located.File == "" {
+			located.File = l.File
+		}
+		if located.Line == 0 {
+			located.Line = l.Num
+			located.Text = l.Text
+		}
+		return &located
+	}
+	return &ParseError{File: l.File, Line: l.Num, Text: l.Text, Msg: err.Error(), Err: err}
+}
@@ -0,0 +1,213 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxLine is the longest line accepted by default.
+// bufio.Scanner alone stops at 64 KiB, which some single-line inputs (day 02) can exceed.
+const DefaultMaxLine = 256 << 20 // 256 MiB
+
+// Config controls how input is read, the zero value is ready to use.
+type Config struct {
+	MaxLine int // longest line in bytes, 0 means DefaultMaxLine
+}
+
+// Lines reads every line of filename with the default config.
+func Lines(filename string) ([]Line, error) {
+	return Config{}.Lines(filename)
+}
+
+// Paragraphs reads filename as blank-line separated blocks with the default config.
+func Paragraphs(filename string) ([][]Line, error) {
+	return Config{}.Paragraphs(filename)
+}
+
+// Ints reads one integer per line with the default config.
+func Ints(filename string) ([]int, error) {
+	return Config{}.Ints(filename)
+}
+
+// CSVInts reads comma separated integers per line with the default config, see Config.CSVInts.
+func CSVInts(filename string, n int) ([][]int, error) {
+	return Config{}.CSVInts(filename, n)
+}
+
+// Fields reads the whitespace separated fields of every line with the default config.
+func Fields(filename string) ([][]string, error) {
+	return Config{}.Fields(filename)
+}
+
+// Lines reads every line of filename, line endings (\n or \r\n) are stripped.
+func (c Config) Lines(filename string) ([]Line, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // error ignored (file only for reading)
+	return c.ReadLines(file, filename)
+}
+
+// ReadLines reads every line of r, name is what errors report as the file.
+func (c Config) ReadLines(r io.Reader, name string) ([]Line, error) {
+	maxLine := c.MaxLine
+	if maxLine <= 0 {
+		maxLine = DefaultMaxLine
+	}
+
+	var lines []Line
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLine)), maxLine)
+	for scanner.Scan() {
+		lines = append(lines, Line{File: name, Num: len(lines) + 1, Text: scanner.Text()})
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &ParseError{
+				File: name,
+				Line: len(lines) + 1,
+				Msg:  fmt.Sprintf("line longer than %d bytes, raise Config.MaxLine", maxLine),
+				Err:  err,
+			}
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return lines, nil
+}
+
+// Paragraphs reads filename as blocks of lines separated by one or more blank lines.
+// Blank lines themselves are dropped, lines keep their original numbers.
+func (c Config) Paragraphs(filename string) ([][]Line, error) {
+	lines, err := c.Lines(filename)
+	if err != nil {
+		return nil, err
+	}
+	var paragraphs [][]Line
+	var cur []Line
+	for _, l := range lines {
+		if strings.TrimSpace(l.Text) == "" {
+			if len(cur) > 0 {
+				paragraphs = append(paragraphs, cur)
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, l)
+	}
+	if len(cur) > 0 {
+		paragraphs = append(paragraphs, cur)
+	}
+	return paragraphs, nil
+}
+
+// Ints reads one integer per line, blank lines are errors.
+func (c Config) Ints(filename string) ([]int, error) {
+	lines, err := c.Lines(filename)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]int, len(lines))
+	for i, l := range lines {
+		if nums[i], err = l.Int(); err != nil {
+			return nil, err
+		}
+	}
+	return nums, nil
+}
+
+// CSVInts reads comma separated integers per line, like days 08/09 `x,y[,z]`.
+// If n > 0 every line must have exactly n values.
+func (c Config) CSVInts(filename string, n int) ([][]int, error) {
+	lines, err := c.Lines(filename)
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]int, len(lines))
+	for i, l := range lines {
+		if records[i], err = l.Ints(",", n); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// Fields reads the whitespace separated fields of every line.
+func (c Config) Fields(filename string) ([][]string, error) {
+	lines, err := c.Lines(filename)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([][]string, len(lines))
+	for i, l := range lines {
+		fields[i], _ = l.Fields()
+	}
+	return fields, nil
+}
+
+// Int parses the whole line (surrounding spaces allowed) as one integer.
+func (l Line) Int() (int, error) {
+	text := strings.TrimSpace(l.Text)
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		col := len(l.Text) - len(strings.TrimLeft(l.Text, " \t")) + 1
+		return 0, l.Errorf(col, "invalid integer %q: %w", text, numError(err))
+	}
+	return n, nil
+}
+
+// Ints splits the line on sep and parses every part as an integer, spaces around parts are allowed.
+// If n > 0 the line must have exactly n parts. Errors point at the offending part.
+func (l Line) Ints(sep string, n int) ([]int, error) {
+	parts := strings.Split(l.Text, sep)
+	if n > 0 && len(parts) != n {
+		return nil, l.Errorf(0, "expected %d values separated by %q, got %d", n, sep, len(parts))
+	}
+	nums := make([]int, len(parts))
+	col := 1
+	for i, part := range parts {
+		text := strings.TrimSpace(part)
+		num, err := strconv.Atoi(text)
+		if err != nil {
+			lead := len(part) - len(strings.TrimLeft(part, " \t"))
+			return nil, l.Errorf(col+lead, "invalid integer %q: %w", text, numError(err))
+		}
+		nums[i] = num
+		col += len(part) + len(sep)
+	}
+	return nums, nil
+}
+
+// Fields splits the line on spaces and tabs like strings.Fields,
+// and also returns the 1-based column each field starts at.
+func (l Line) Fields() (fields []string, cols []int) {
+	start := -1
+	for i := 0; i <= len(l.Text); i++ {
+		if i < len(l.Text) && l.Text[i] != ' ' && l.Text[i] != '\t' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			fields = append(fields, l.Text[start:i])
+			cols = append(cols, start+1)
+			start = -1
+		}
+	}
+	return fields, cols
+}
+
+// numError drops the strconv prefix, the ParseError already says what was parsed
+func numError(err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return ne.Err
+	}
+	return err
+}
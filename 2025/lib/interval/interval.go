@@ -0,0 +1,167 @@
+// Package interval keeps sets of integers as sorted closed intervals [Lo, Hi].
+//
+// A Set never holds overlapping or touching intervals: inserting 1-5 and 6-8 gives 1-8.
+// That keeps lookups a binary search and makes two sets equal exactly when their intervals are.
+// Nothing computes Hi+1 or Lo-1 where it could wrap, so intervals may reach the very ends
+// of their type, and lengths come back as checked.Int as they can outgrow it.
+package interval
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sort"
+
+	"aoclib/checked"
+	"aoclib/intmath"
+)
+
+// Interval is the closed range of integers from Lo to Hi, both included.
+type Interval[T intmath.Integer] struct {
+	Lo, Hi T
+}
+
+// Len returns how many integers the interval holds, Hi-Lo+1.
+func (iv Interval[T]) Len() checked.Int {
+	return toChecked(iv.Hi).Sub(toChecked(iv.Lo)).Add(checked.NewInt(1))
+}
+
+// Set is a set of integers kept as intervals, the zero value is the empty set.
+type Set[T intmath.Integer] struct {
+	ivs []Interval[T] // sorted, disjoint and never touching
+}
+
+// Of returns the set of the union of the given intervals, which may overlap and come in any order.
+func Of[T intmath.Integer](ivs ...Interval[T]) *Set[T] {
+	sorted := slices.Clone(ivs)
+	slices.SortFunc(sorted, func(a, b Interval[T]) int {
+		return cmp.Compare(a.Lo, b.Lo) // not a.Lo-b.Lo, that overflows for far apart values
+	})
+	s := &Set[T]{}
+	for _, iv := range sorted {
+		if iv.Lo > iv.Hi {
+			continue
+		}
+		// sorted by start, so each one can only merge into the last
+		if n := len(s.ivs); n > 0 && touches(s.ivs[n-1].Hi, iv.Lo) {
+			s.ivs[n-1].Hi = max(s.ivs[n-1].Hi, iv.Hi)
+		} else {
+			s.ivs = append(s.ivs, iv)
+		}
+	}
+	return s
+}
+
+// Insert adds lo..hi to the set, merging it with the intervals it overlaps or touches.
+// An empty range (lo > hi) changes nothing.
+func (s *Set[T]) Insert(lo, hi T) {
+	if lo > hi {
+		return
+	}
+	// first interval that ends at or after lo-1, the first that could merge
+	i := sort.Search(len(s.ivs), func(i int) bool { return touches(s.ivs[i].Hi, lo) })
+	// first interval that starts after hi+1, the first that can't
+	j := sort.Search(len(s.ivs), func(j int) bool { return !touches(hi, s.ivs[j].Lo) })
+	if i < j {
+		lo = min(lo, s.ivs[i].Lo)
+		hi = max(hi, s.ivs[j-1].Hi)
+	}
+	s.ivs = slices.Replace(s.ivs, i, j, Interval[T]{lo, hi})
+}
+
+// Delete removes lo..hi from the set, splitting the interval it falls in the middle of.
+// An empty range (lo > hi) changes nothing.
+func (s *Set[T]) Delete(lo, hi T) {
+	if lo > hi {
+		return
+	}
+	// intervals i..j-1 overlap lo..hi
+	i := sort.Search(len(s.ivs), func(i int) bool { return s.ivs[i].Hi >= lo })
+	j := sort.Search(len(s.ivs), func(j int) bool { return s.ivs[j].Lo > hi })
+	if i >= j {
+		return
+	}
+	var keep []Interval[T] // the parts sticking out on either side, lo-1 and hi+1 can't wrap there
+	if first := s.ivs[i]; first.Lo < lo {
+		keep = append(keep, Interval[T]{first.Lo, lo - 1})
+	}
+	if last := s.ivs[j-1]; last.Hi > hi {
+		keep = append(keep, Interval[T]{hi + 1, last.Hi})
+	}
+	s.ivs = slices.Replace(s.ivs, i, j, keep...)
+}
+
+// Contains reports whether x is in the set.
+func (s *Set[T]) Contains(x T) bool {
+	i := sort.Search(len(s.ivs), func(i int) bool { return s.ivs[i].Hi >= x })
+	return i < len(s.ivs) && s.ivs[i].Lo <= x
+}
+
+// Union returns a new set of the integers in s, o or both.
+func (s *Set[T]) Union(o *Set[T]) *Set[T] {
+	return Of(append(slices.Clone(s.ivs), o.ivs...)...)
+}
+
+// Intersect returns a new set of the integers in both s and o.
+func (s *Set[T]) Intersect(o *Set[T]) *Set[T] {
+	// walk both in order, the one that ends first can't overlap anything further in the other
+	r := &Set[T]{}
+	for i, j := 0, 0; i < len(s.ivs) && j < len(o.ivs); {
+		a, b := s.ivs[i], o.ivs[j]
+		if lo, hi := max(a.Lo, b.Lo), min(a.Hi, b.Hi); lo <= hi {
+			r.ivs = append(r.ivs, Interval[T]{lo, hi}) // still disjoint and not touching, as a and b were
+		}
+		if a.Hi < b.Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return r
+}
+
+// Complement returns a new set of the integers in lo..hi that are not in s.
+func (s *Set[T]) Complement(lo, hi T) *Set[T] {
+	r := Of(Interval[T]{lo, hi})
+	for _, iv := range s.ivs {
+		r.Delete(iv.Lo, iv.Hi)
+	}
+	return r
+}
+
+// Len returns how many integers the set holds.
+func (s *Set[T]) Len() checked.Int {
+	var total checked.Int
+	for _, iv := range s.ivs {
+		total = total.Add(iv.Len())
+	}
+	return total
+}
+
+// Count returns how many intervals the set is made of.
+func (s *Set[T]) Count() int {
+	return len(s.ivs)
+}
+
+// All iterates over the intervals of the set in increasing order.
+func (s *Set[T]) All() iter.Seq[Interval[T]] {
+	return slices.Values(s.ivs)
+}
+
+// Intervals returns a copy of the intervals of the set in increasing order.
+func (s *Set[T]) Intervals() []Interval[T] {
+	return slices.Clone(s.ivs)
+}
+
+// touches reports whether an interval ending at hi and one starting at lo overlap or are adjacent
+func touches[T intmath.Integer](hi, lo T) bool {
+	return lo <= hi || lo-1 == hi // lo > hi here, so lo-1 can't wrap
+}
+
+// toChecked converts any integer to a checked.Int without losing the top half of unsigned types
+func toChecked[T intmath.Integer](x T) checked.Int {
+	if x < 0 || T(0)-1 < 0 { // signed, fits an int64
+		return checked.NewInt(int(int64(x)))
+	}
+	return checked.FromUint64(uint64(x))
+}
@@ -0,0 +1,208 @@
+package interval
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"aoclib/checked"
+)
+
+// the tests run on int8 so a [256]bool bitmap can stand in for any set,
+// and the ends of the type, where lo-1 and hi+1 would wrap, come up all the time
+
+type bitmap [256]bool
+
+func (b *bitmap) set(lo, hi int8, v bool) {
+	for x := int(lo); x <= int(hi); x++ {
+		b[x-math.MinInt8] = v
+	}
+}
+
+// randomBound leans towards the ends of int8 and a few values around 0
+func randomBound(rng *rand.Rand) int8 {
+	switch rng.Intn(4) {
+	case 0:
+		return int8(math.MinInt8 + rng.Intn(3))
+	case 1:
+		return int8(math.MaxInt8 - rng.Intn(3))
+	case 2:
+		return int8(rng.Intn(11) - 5)
+	}
+	return int8(rng.Intn(256) + math.MinInt8)
+}
+
+// randomRange is mostly short ranges, sometimes empty (lo > hi) ones
+func randomRange(rng *rand.Rand) (lo, hi int8) {
+	lo = randomBound(rng)
+	if rng.Intn(10) == 0 {
+		return lo, randomBound(rng)
+	}
+	return lo, int8(min(int(lo)+rng.Intn(20), math.MaxInt8))
+}
+
+// checkSet compares s with want and checks the intervals are sorted, not empty and never touch
+func checkSet(t *testing.T, s *Set[int8], want *bitmap, what string) {
+	t.Helper()
+	ivs := s.Intervals()
+	for i, iv := range ivs {
+		if iv.Lo > iv.Hi {
+			t.Fatalf("%s: empty interval %v in %v", what, iv, ivs)
+		}
+		if i > 0 && int(ivs[i-1].Hi)+1 >= int(iv.Lo) {
+			t.Fatalf("%s: intervals %v and %v overlap, touch or are out of order in %v", what, ivs[i-1], iv, ivs)
+		}
+	}
+
+	count, runs := 0, 0
+	for i, in := range want {
+		if in {
+			count++
+			if i == 0 || !want[i-1] {
+				runs++
+			}
+		}
+		if x := int8(i + math.MinInt8); s.Contains(x) != in {
+			t.Fatalf("%s: Contains(%d) = %v, want %v, set is %v", what, x, !in, in, ivs)
+		}
+	}
+	if s.Count() != runs {
+		t.Fatalf("%s: Count() = %d, want %d, set is %v", what, s.Count(), runs, ivs)
+	}
+	if s.Len().Cmp(checked.NewInt(count)) != 0 {
+		t.Fatalf("%s: Len() = %d, want %d, set is %v", what, s.Len(), count, ivs)
+	}
+	if all := slices.Collect(s.All()); !slices.Equal(all, ivs) {
+		t.Fatalf("%s: All() = %v, Intervals() = %v", what, all, ivs)
+	}
+}
+
+// randomSet builds a set from random Insert and Delete calls, checking it after every call
+func randomSet(t *testing.T, rng *rand.Rand, ops int) (*Set[int8], *bitmap) {
+	t.Helper()
+	s, want := &Set[int8]{}, &bitmap{}
+	for range ops {
+		lo, hi := randomRange(rng)
+		if rng.Intn(3) == 0 {
+			s.Delete(lo, hi)
+			want.set(lo, hi, false)
+			checkSet(t, s, want, "Delete")
+		} else {
+			s.Insert(lo, hi)
+			want.set(lo, hi, true)
+			checkSet(t, s, want, "Insert")
+		}
+	}
+	return s, want
+}
+
+func TestSetInsertDelete(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 2000 {
+		randomSet(t, rng, 1+rng.Intn(30))
+	}
+}
+
+func TestSetOf(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for range 2000 {
+		var ivs []Interval[int8]
+		want := &bitmap{}
+		for range rng.Intn(20) {
+			lo, hi := randomRange(rng)
+			ivs = append(ivs, Interval[int8]{lo, hi})
+			want.set(lo, hi, true)
+		}
+		checkSet(t, Of(ivs...), want, "Of")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for range 2000 {
+		a, wantA := randomSet(t, rng, rng.Intn(15))
+		b, wantB := randomSet(t, rng, rng.Intn(15))
+		lo, hi := randomRange(rng)
+
+		var union, inter, comp bitmap
+		for i := range union {
+			union[i] = wantA[i] || wantB[i]
+			inter[i] = wantA[i] && wantB[i]
+		}
+		comp.set(lo, hi, true)
+		for i := range comp {
+			comp[i] = comp[i] && !wantA[i]
+		}
+		checkSet(t, a.Union(b), &union, "Union")
+		checkSet(t, a.Intersect(b), &inter, "Intersect")
+		checkSet(t, a.Complement(lo, hi), &comp, "Complement")
+
+		// the operations make new sets, a and b stay as they were
+		checkSet(t, a, wantA, "Union/Intersect/Complement changed a")
+		checkSet(t, b, wantB, "Union/Intersect/Complement changed b")
+	}
+}
+
+func TestIntervalLen(t *testing.T) {
+	tests := []struct {
+		iv   Interval[int8]
+		want int
+	}{
+		{Interval[int8]{0, 0}, 1},
+		{Interval[int8]{-5, 5}, 11},
+		{Interval[int8]{math.MinInt8, math.MaxInt8}, 256},
+	}
+	for _, tt := range tests {
+		if got := tt.iv.Len(); got.Cmp(checked.NewInt(tt.want)) != 0 {
+			t.Errorf("%v.Len() = %d, want %d", tt.iv, got, tt.want)
+		}
+	}
+	// the whole uint64 range holds 2^64 integers, one more than a uint64 holds
+	all := Interval[uint64]{0, math.MaxUint64}.Len()
+	if want := checked.FromUint64(math.MaxUint64).Add(checked.NewInt(1)); all.Cmp(want) != 0 {
+		t.Errorf("full uint64 Len() = %d, want %d", all, want)
+	}
+}
+
+// overlappingScan is the reference for Tree: every entry, in order of Lo then of input.
+// an empty query (lo > hi) overlaps nothing
+func overlappingScan(entries []Entry[int8, int], lo, hi int8) []Entry[int8, int] {
+	if lo > hi {
+		return nil
+	}
+	var found []Entry[int8, int]
+	for _, e := range entries {
+		if e.Lo <= e.Hi && e.Lo <= hi && e.Hi >= lo {
+			found = append(found, e)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b Entry[int8, int]) int { return cmp.Compare(a.Lo, b.Lo) })
+	return found
+}
+
+func TestTree(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for range 2000 {
+		entries := make([]Entry[int8, int], rng.Intn(40))
+		for i := range entries {
+			lo, hi := randomRange(rng) // empty entries included, they never match
+			entries[i] = Entry[int8, int]{Interval[int8]{lo, hi}, i}
+		}
+		tree := NewTree(entries)
+		if tree.Len() != len(entries) {
+			t.Fatalf("Len() = %d, want %d", tree.Len(), len(entries))
+		}
+
+		for range 30 {
+			lo, hi := randomRange(rng)
+			if got, want := tree.Overlapping(lo, hi), overlappingScan(entries, lo, hi); !slices.Equal(got, want) {
+				t.Fatalf("Overlapping(%d, %d) = %v, want %v, entries %v", lo, hi, got, want, entries)
+			}
+			if got, want := tree.Stab(lo), overlappingScan(entries, lo, lo); !slices.Equal(got, want) {
+				t.Fatalf("Stab(%d) = %v, want %v, entries %v", lo, got, want, entries)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package interval
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+	"sort"
+
+	"aoclib/intmath"
+)
+
+// Entry is an interval with a value attached, like the input line it came from.
+type Entry[T intmath.Integer, V any] struct {
+	Interval[T]
+	Value V
+}
+
+// Tree finds the entries overlapping a point or an interval, unlike a Set it keeps every entry
+// as it was given, overlaps included, so it can tell which of them matched.
+//
+// Entries are sorted by Lo, so the ones that can overlap lo..hi are a prefix: those with Lo <= hi.
+// Of those, the matches are the ones with Hi >= lo. Picture the prefix as a tree rooted at its
+// entry with the largest Hi, whose left and right subtrees are the entries before and after it
+// (a Cartesian tree by Hi): if the root ends before lo, so does everything under it, otherwise
+// it is a match and both sides are worth a look. A sparse table finds the largest Hi of any
+// stretch in O(1), so a query is a binary search plus O(1) per match, O(log n + k) in total.
+type Tree[T intmath.Integer, V any] struct {
+	entries []Entry[T, V] // sorted by Lo, ties in the order given
+	maxAt   [][]int       // maxAt[j][i] is the entry with the largest Hi among i..i+2^j-1
+}
+
+// NewTree indexes entries, empty ones (Lo > Hi) never match anything.
+func NewTree[T intmath.Integer, V any](entries []Entry[T, V]) *Tree[T, V] {
+	t := &Tree[T, V]{entries: slices.Clone(entries)}
+	slices.SortStableFunc(t.entries, func(a, b Entry[T, V]) int {
+		return cmp.Compare(a.Lo, b.Lo)
+	})
+
+	n := len(t.entries)
+	t.maxAt = [][]int{make([]int, n)}
+	for i := range n {
+		t.maxAt[0][i] = i
+	}
+	for j := 1; 1<<j <= n; j++ {
+		prev, level := t.maxAt[j-1], make([]int, n-1<<j+1)
+		for i := range level {
+			level[i] = t.longer(prev[i], prev[i+1<<(j-1)])
+		}
+		t.maxAt = append(t.maxAt, level)
+	}
+	return t
+}
+
+// Len returns how many entries the tree holds.
+func (t *Tree[T, V]) Len() int {
+	return len(t.entries)
+}
+
+// Stab returns the entries containing x, sorted by Lo.
+func (t *Tree[T, V]) Stab(x T) []Entry[T, V] {
+	return t.Overlapping(x, x)
+}
+
+// Overlapping returns the entries sharing at least one integer with lo..hi, sorted by Lo.
+func (t *Tree[T, V]) Overlapping(lo, hi T) []Entry[T, V] {
+	if lo > hi {
+		return nil
+	}
+	end := sort.Search(len(t.entries), func(i int) bool { return t.entries[i].Lo > hi })
+	var found []Entry[T, V]
+	t.collect(0, end, lo, &found)
+	return found
+}
+
+// collect adds the entries of from..to-1 that end at or after lo, in order
+func (t *Tree[T, V]) collect(from, to int, lo T, found *[]Entry[T, V]) {
+	if from >= to {
+		return
+	}
+	root := t.longest(from, to)
+	if e := t.entries[root]; e.Hi < lo || e.Lo > e.Hi {
+		return // nothing here reaches lo, an empty entry as the longest means all are empty
+	}
+	t.collect(from, root, lo, found)
+	*found = append(*found, t.entries[root])
+	t.collect(root+1, to, lo, found)
+}
+
+// longest returns the entry with the largest Hi among from..to-1, two overlapping table lookups
+func (t *Tree[T, V]) longest(from, to int) int {
+	j := bits.Len(uint(to-from)) - 1
+	return t.longer(t.maxAt[j][from], t.maxAt[j][to-1<<j])
+}
+
+// longer returns whichever of entries a and b reaches further, empty entries never win
+func (t *Tree[T, V]) longer(a, b int) int {
+	ea, eb := t.entries[a], t.entries[b]
+	switch {
+	case ea.Lo > ea.Hi:
+		return b
+	case eb.Lo > eb.Hi:
+		return a
+	case eb.Hi > ea.Hi:
+		return b
+	}
+	return a
+}
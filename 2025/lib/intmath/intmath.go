@@ -0,0 +1,87 @@
+// Package intmath has the integer helpers Go leaves out, generic over every integer type.
+//
+// Everything stays in integers: no detour through float64, so results are exact over the
+// whole range of the type. Like the built-in operators, results that do not fit wrap around,
+// use aoclib/checked when that matters.
+package intmath
+
+// Integer is any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// FloorDiv returns a/b rounded towards negative infinity, where Go's / truncates towards zero:
+// FloorDiv(-1, 100) is -1, not 0. It panics if b is 0.
+func FloorDiv[T Integer](a, b T) T {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// Mod returns a modulo m with the sign of m, where Go's % takes the sign of a:
+// Mod(-1, 100) is 99, not -1. It panics if m is 0.
+func Mod[T Integer](a, m T) T {
+	r := a % m
+	if r != 0 && (r < 0) != (m < 0) {
+		r += m
+	}
+	return r
+}
+
+// Abs returns the absolute value of a, Abs of the most negative value wraps to itself.
+func Abs[T Integer](a T) T {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// AbsDiff returns |a-b|, also for unsigned types where a-b would wrap.
+func AbsDiff[T Integer](a, b T) T {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// GCD returns the greatest common divisor of a and b, GCD(0, 0) is 0.
+// It is never negative, unless the answer is the most negative value itself (it does not fit).
+func GCD[T Integer](a, b T) T {
+	for b != 0 { // % works on negative values too, Abs only at the end so it cannot wrap midway
+		a, b = b, a%b
+	}
+	return Abs(a)
+}
+
+// LCM returns the least common multiple of a and b, 0 if either is 0.
+func LCM[T Integer](a, b T) T {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return Abs(a / GCD(a, b) * b)
+}
+
+// Pow returns base**exp by repeated squaring, Pow(x, 0) is 1.
+func Pow[T Integer](base T, exp uint) T {
+	result := T(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+// NumDigits returns how many decimal digits |n| has, NumDigits(0) is 1.
+func NumDigits[T Integer](n T) int {
+	digits := 1
+	for n /= 10; n != 0; n /= 10 { // dividing first keeps the most negative value from wrapping
+		digits++
+	}
+	return digits
+}
@@ -0,0 +1,230 @@
+package intmath
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// every int8 and uint8 value, the types are small enough to try every pair
+var (
+	allInt8  = make([]int8, 0, 256)
+	allUint8 = make([]uint8, 0, 256)
+)
+
+func init() {
+	for v := math.MinInt8; v <= math.MaxInt8; v++ {
+		allInt8 = append(allInt8, int8(v))
+	}
+	for v := 0; v <= math.MaxUint8; v++ {
+		allUint8 = append(allUint8, uint8(v))
+	}
+}
+
+// floorDivMod is the reference: big.Int's Euclidean division (0 <= r < |b|) turned into
+// flooring, where r takes the sign of b
+func floorDivMod(a, b int64) (q, r int64) {
+	bq, br := new(big.Int).DivMod(big.NewInt(a), big.NewInt(b), new(big.Int))
+	if b < 0 && br.Sign() != 0 {
+		bq.Sub(bq, big.NewInt(1))
+		br.Add(br, big.NewInt(b))
+	}
+	return bq.Int64(), br.Int64()
+}
+
+func TestFloorDivModInt8(t *testing.T) {
+	for _, a := range allInt8 {
+		for _, b := range allInt8 {
+			if b == 0 {
+				continue
+			}
+			q, r := floorDivMod(int64(a), int64(b))
+			// MinInt8 / -1 is 128, which wraps to MinInt8 like Go's own / does
+			if got, want := FloorDiv(a, b), int8(q); got != want {
+				t.Fatalf("FloorDiv(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			if got, want := Mod(a, b), int8(r); got != want {
+				t.Fatalf("Mod(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestFloorDivModUint8(t *testing.T) {
+	for _, a := range allUint8 {
+		for _, b := range allUint8 {
+			if b == 0 {
+				continue
+			}
+			q, r := floorDivMod(int64(a), int64(b))
+			if got, want := FloorDiv(a, b), uint8(q); got != want {
+				t.Fatalf("FloorDiv(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			if got, want := Mod(a, b), uint8(r); got != want {
+				t.Fatalf("Mod(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestFloorDivModExamples(t *testing.T) {
+	tests := []struct {
+		a, b, q, r int
+	}{
+		{-1, 100, -1, 99},
+		{1, -100, -1, -99},
+		{-1, -100, 0, -1},
+		{7, 2, 3, 1},
+		{-7, 2, -4, 1},
+		{7, -2, -4, -1},
+		{-7, -2, 3, -1},
+		{-6, 3, -2, 0},
+		{math.MinInt, 1, math.MinInt, 0},
+		{math.MinInt, math.MaxInt, -2, math.MaxInt - 1},
+		{math.MaxInt, math.MinInt, -1, -1},
+	}
+	for _, tt := range tests {
+		if q := FloorDiv(tt.a, tt.b); q != tt.q {
+			t.Errorf("FloorDiv(%d, %d) = %d, want %d", tt.a, tt.b, q, tt.q)
+		}
+		if r := Mod(tt.a, tt.b); r != tt.r {
+			t.Errorf("Mod(%d, %d) = %d, want %d", tt.a, tt.b, r, tt.r)
+		}
+	}
+}
+
+func TestAbs(t *testing.T) {
+	for _, a := range allInt8 {
+		want := int8(max(int(a), -int(a))) // MinInt8 wraps to itself
+		if got := Abs(a); got != want {
+			t.Fatalf("Abs(%d) = %d, want %d", a, got, want)
+		}
+	}
+	for _, a := range allUint8 {
+		for _, b := range allUint8 {
+			want := uint8(max(int(a)-int(b), int(b)-int(a)))
+			if got := AbsDiff(a, b); got != want {
+				t.Fatalf("AbsDiff(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+// gcdBrute tries every divisor, lcmBrute every multiple
+func gcdBrute(a, b int) int {
+	a, b = max(a, -a), max(b, -b)
+	for d := max(a, b); d > 1; d-- {
+		if a%d == 0 && b%d == 0 {
+			return d
+		}
+	}
+	if a == 0 && b == 0 {
+		return 0
+	}
+	return 1
+}
+
+func lcmBrute(a, b int) int {
+	a, b = max(a, -a), max(b, -b)
+	if a == 0 || b == 0 {
+		return 0
+	}
+	m := a
+	for m%b != 0 {
+		m += a
+	}
+	return m
+}
+
+func TestGCDLCMInt8(t *testing.T) {
+	for _, a := range allInt8 {
+		for _, b := range allInt8 {
+			// GCD(-128, 0) and GCD(-128, -128) are 128, which wraps to -128 as documented
+			if got, want := GCD(a, b), int8(gcdBrute(int(a), int(b))); got != want {
+				t.Fatalf("GCD(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			// an LCM that doesn't fit has no meaningful wrapped value, only check those that do
+			l := lcmBrute(int(a), int(b))
+			if l > math.MaxInt8 {
+				continue
+			}
+			if got, want := LCM(a, b), int8(l); got != want {
+				t.Fatalf("LCM(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestGCDLCMUint8(t *testing.T) {
+	for _, a := range allUint8 {
+		for _, b := range allUint8 {
+			if got, want := GCD(a, b), uint8(gcdBrute(int(a), int(b))); got != want {
+				t.Fatalf("GCD(%d, %d) = %d, want %d", a, b, got, want)
+			}
+			l := lcmBrute(int(a), int(b))
+			if l > math.MaxUint8 {
+				continue
+			}
+			if got, want := LCM(a, b), uint8(l); got != want {
+				t.Fatalf("LCM(%d, %d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestPow(t *testing.T) {
+	for _, base := range allInt8 {
+		want := int8(1)
+		for exp := range uint(20) {
+			if got := Pow(base, exp); got != want {
+				t.Fatalf("Pow(%d, %d) = %d, want %d", base, exp, got, want)
+			}
+			want *= base // wraps the same way
+		}
+	}
+	for _, base := range []int64{-3, -1, 0, 1, 2, 3, 7, 10} {
+		want := int64(1)
+		for exp := range uint(70) {
+			if got := Pow(base, exp); got != want {
+				t.Fatalf("Pow(%d, %d) = %d, want %d", base, exp, got, want)
+			}
+			want *= base
+		}
+	}
+}
+
+func TestNumDigits(t *testing.T) {
+	// around every power of 10 that fits, both signs
+	p := int64(1)
+	for k := 1; k <= 19; k++ {
+		for _, n := range []int64{p - 1, p, p + 1, -(p - 1), -p, -(p + 1)} {
+			want := len(strings.TrimPrefix(strconv.FormatInt(n, 10), "-"))
+			if got := NumDigits(n); got != want {
+				t.Fatalf("NumDigits(%d) = %d, want %d", n, got, want)
+			}
+		}
+		if k < 19 {
+			p *= 10
+		}
+	}
+
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"0", NumDigits(0), 1},
+		{"MinInt", NumDigits(math.MinInt), 19},
+		{"MaxInt", NumDigits(math.MaxInt), 19},
+		{"MinInt8", NumDigits(int8(math.MinInt8)), 3},
+		{"MaxUint64", NumDigits(uint64(math.MaxUint64)), 20},
+		{"MaxUint8", NumDigits(uint8(math.MaxUint8)), 3},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("NumDigits(%s) = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Package parse is a small parser-combinator library for one line of puzzle input.
+//
+// A Parser is a plain function over a Scanner, so parsers compose with ordinary Go code:
+//
+//	from, err := parse.Ident(s)
+//	if err == nil {
+//		_, err = parse.Token(":")(s)
+//	}
+//
+// Spaces and tabs between tokens are skipped, so odd spacing does not matter.
+// A primitive parser that fails leaves the scanner where it was, which lets Many
+// and Matches try a parser and back off. Errors are *input.ParseError values that
+// point at the furthest column reached and list everything that would have fit there:
+//
+//	input:3:14: unexpected 'x', expected ',' or ')'
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"aoclib/input"
+)
+
+// Parser reads a T from the scanner.
+type Parser[T any] func(s *Scanner) (T, error)
+
+// Scanner is the position in the line being parsed.
+type Scanner struct {
+	line     input.Line
+	pos      int // byte offset into line.Text
+	failPos  int // furthest offset where a parser failed, -1 if none yet
+	expected []string
+}
+
+func newScanner(line input.Line) *Scanner {
+	return &Scanner{line: line, failPos: -1}
+}
+
+// Parse runs p on the whole line, anything left over after p is an error.
+func Parse[T any](line input.Line, p Parser[T]) (T, error) {
+	s := newScanner(line)
+	v, err := p(s)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if err := s.End(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// Matches reports whether p accepts the start of line, the rest of the line is not looked at.
+func Matches[T any](line input.Line, p Parser[T]) bool {
+	_, err := p(newScanner(line))
+	return err == nil
+}
+
+// Col returns the 1-based column of the next token.
+func (s *Scanner) Col() int {
+	return s.skip() + 1
+}
+
+// Errorf builds an error at column col, for checks a parser cannot express (lengths, ranges, ...).
+func (s *Scanner) Errorf(col int, format string, args ...any) *input.ParseError {
+	return s.line.Errorf(col, format, args...)
+}
+
+// End fails unless only spaces are left.
+func (s *Scanner) End() error {
+	at := s.skip()
+	if at < len(s.line.Text) {
+		return s.fail(at, "end of line")
+	}
+	s.pos = at
+	return nil
+}
+
+// skip returns the offset of the next non-space byte without moving the scanner
+func (s *Scanner) skip() int {
+	at := s.pos
+	for at < len(s.line.Text) && (s.line.Text[at] == ' ' || s.line.Text[at] == '\t') {
+		at++
+	}
+	return at
+}
+
+// fail records that what was expected at offset at and builds the error for the furthest failure,
+// so a Many that stopped early still explains what it would have accepted
+func (s *Scanner) fail(at int, what string) error {
+	if at > s.failPos {
+		s.failPos, s.expected = at, nil
+	}
+	if at == s.failPos && !contains(s.expected, what) {
+		s.expected = append(s.expected, what)
+	}
+	return s.Errorf(s.failPos+1, "unexpected %s, expected %s", s.describe(s.failPos), orList(s.expected))
+}
+
+func (s *Scanner) describe(at int) string {
+	if at >= len(s.line.Text) {
+		return "end of line"
+	}
+	return fmt.Sprintf("%q", s.line.Text[at])
+}
+
+// Token matches tok exactly.
+func Token(tok string) Parser[string] {
+	return func(s *Scanner) (string, error) {
+		at := s.skip()
+		if !strings.HasPrefix(s.line.Text[at:], tok) {
+			return "", s.fail(at, quote(tok))
+		}
+		s.pos = at + len(tok)
+		return tok, nil
+	}
+}
+
+// OneOf matches a single byte out of chars, an empty chars never matches.
+func OneOf(chars string) Parser[byte] {
+	return func(s *Scanner) (byte, error) {
+		at := s.skip()
+		if chars == "" {
+			return 0, s.Errorf(at+1, "OneOf has no characters to match")
+		}
+		if at < len(s.line.Text) && strings.IndexByte(chars, s.line.Text[at]) >= 0 {
+			s.pos = at + 1
+			return s.line.Text[at], nil
+		}
+		var err error
+		for i := range len(chars) {
+			err = s.fail(at, fmt.Sprintf("%q", chars[i]))
+		}
+		return 0, err
+	}
+}
+
+// Uint matches a non-negative decimal number.
+func Uint(s *Scanner) (int, error) {
+	return number(s, false)
+}
+
+// Int matches a decimal number with an optional sign.
+func Int(s *Scanner) (int, error) {
+	return number(s, true)
+}
+
+func number(s *Scanner, signed bool) (int, error) {
+	at := s.skip()
+	end := at
+	if signed && end < len(s.line.Text) && (s.line.Text[end] == '-' || s.line.Text[end] == '+') {
+		end++
+	}
+	digits := end
+	for end < len(s.line.Text) && isDigit(s.line.Text[end]) {
+		end++
+	}
+	if end == digits {
+		return 0, s.fail(at, "number")
+	}
+	n, err := strconv.Atoi(s.line.Text[at:end])
+	if err != nil {
+		return 0, s.Errorf(at+1, "invalid number %q: %w", s.line.Text[at:end], err.(*strconv.NumError).Err)
+	}
+	s.pos = end
+	return n, nil
+}
+
+// Ident matches an identifier: a letter followed by letters, digits or underscores.
+func Ident(s *Scanner) (string, error) {
+	at := s.skip()
+	end := at
+	for end < len(s.line.Text) {
+		c := s.line.Text[end]
+		if !isLetter(c) && (end == at || (!isDigit(c) && c != '_')) {
+			break
+		}
+		end++
+	}
+	if end == at {
+		return "", s.fail(at, "identifier")
+	}
+	s.pos = end
+	return s.line.Text[at:end], nil
+}
+
+// Many applies p as long as it matches, zero times is fine.
+// It stops when p fails without consuming anything, a failure halfway through an item is returned.
+// It also stops when p matches without consuming anything, that match would repeat forever
+// (like a Many inside a Many), so it is dropped.
+func Many[T any](p Parser[T]) Parser[[]T] {
+	return func(s *Scanner) ([]T, error) {
+		var items []T
+		for {
+			start, next := s.pos, s.skip()
+			v, err := p(s)
+			if err != nil {
+				if s.pos != start {
+					return nil, err
+				}
+				return items, nil
+			}
+			if s.pos <= next { // only spaces, if anything
+				s.pos = start
+				return items, nil
+			}
+			items = append(items, v)
+		}
+	}
+}
+
+// Pos is a parsed value together with the column it starts at.
+type Pos[T any] struct {
+	Col int
+	Val T
+}
+
+// WithPos wraps p so its value remembers its column, for checks done after parsing.
+func WithPos[T any](p Parser[T]) Parser[Pos[T]] {
+	return func(s *Scanner) (Pos[T], error) {
+		col := s.Col()
+		v, err := p(s)
+		return Pos[T]{col, v}, err
+	}
+}
+
+// Delimited matches open, items separated by sep, then close, like `(1,3)` or `{3,5,4,7}`.
+// An empty sep means the items follow each other directly, like `[.##.]`. The list may be empty.
+func Delimited[T any](open, sep, close string, item Parser[T]) Parser[[]T] {
+	return func(s *Scanner) ([]T, error) {
+		if _, err := Token(open)(s); err != nil {
+			return nil, err
+		}
+		var items []T
+		if sep == "" {
+			var err error
+			if items, err = Many(item)(s); err != nil {
+				return nil, err
+			}
+		} else if _, err := Token(close)(s); err == nil {
+			return items, nil
+		} else {
+			for {
+				v, err := item(s)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+				if _, err := Token(sep)(s); err != nil {
+					break
+				}
+			}
+		}
+		if _, err := Token(close)(s); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// quote shows single characters the same way OneOf does
+func quote(tok string) string {
+	if len(tok) == 1 {
+		return fmt.Sprintf("%q", tok[0])
+	}
+	return strconv.Quote(tok)
+}
+
+// orList joins like `'a', 'b' or 'c'`
+func orList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
@@ -0,0 +1,119 @@
+package parse
+
+import (
+	"errors"
+	"testing"
+
+	"aoclib/input"
+)
+
+func line(text string) input.Line {
+	return input.Line{File: "t", Num: 3, Text: text}
+}
+
+// checkErr checks err is a ParseError at col with message msg
+func checkErr(t *testing.T, text string, err error, col int, msg string) {
+	t.Helper()
+	var pe *input.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("%q: got %v, want a *input.ParseError", text, err)
+	}
+	if pe.Line != 3 || pe.Col != col || pe.Msg != msg {
+		t.Errorf("%q: got %d:%d: %s, want 3:%d: %s", text, pe.Line, pe.Col, pe.Msg, col, msg)
+	}
+}
+
+func TestErrorColumns(t *testing.T) {
+	bits := Delimited("[", "", "]", OneOf(".#"))
+	nums := Delimited("(", ",", ")", Uint)
+	for _, tc := range []struct {
+		name string
+		p    Parser[any]
+		text string
+		col  int
+		msg  string
+	}{
+		{"token", erase(Token("ab")), "  ax", 3, `unexpected 'a', expected "ab"`},
+		{"token at end", erase(Token(":")), "", 1, "unexpected end of line, expected ':'"},
+		{"token left over", erase(Token("a")), "a ,", 3, "unexpected ',', expected end of line"},
+		{"delimited open", erase(nums), " 1,2)", 2, "unexpected '1', expected '('"},
+		{"delimited item", erase(nums), "(1,x)", 4, "unexpected 'x', expected number"},
+		{"delimited close", erase(nums), "(1, 2", 6, "unexpected end of line, expected ',' or ')'"},
+		{"delimited no sep", erase(bits), "[.#x]", 4, "unexpected 'x', expected '.', '#' or ']'"},
+		{"many stops", erase(Many(Token("ab"))), "ab abac", 6, `unexpected 'a', expected "ab" or end of line`},
+		{"many inside item", erase(Many(nums)), "(1) (2,x)", 8, "unexpected 'x', expected number"},
+		{"oneof empty", erase(OneOf("")), "x", 1, "OneOf has no characters to match"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(line(tc.text), tc.p)
+			checkErr(t, tc.text, err, tc.col, tc.msg)
+		})
+	}
+}
+
+// erase lets parsers of different types share a table
+func erase[T any](p Parser[T]) Parser[any] {
+	return func(s *Scanner) (any, error) { return p(s) }
+}
+
+func TestManyWithoutProgress(t *testing.T) {
+	// these used to loop forever: the inner parser matches without consuming anything
+	if v, err := Parse(line("aa a"), Many(Many(Token("a")))); err != nil || len(v) != 1 || len(v[0]) != 3 {
+		t.Errorf("Many(Many(a)) on \"aa a\" = %q, %v, want one item of three", v, err)
+	}
+	if _, err := Parse(line("x"), Many(Many(Token("a")))); err == nil {
+		t.Errorf("Many(Many(a)) on \"x\" left the x unparsed without an error")
+	}
+	if v, err := Parse(line("  "), Many(Token(""))); err != nil || len(v) != 0 {
+		t.Errorf("Many(\"\") on spaces = %q, %v, want no items", v, err)
+	}
+	checkErr(t, "x", func() error { _, err := Parse(line("x"), Many(OneOf(""))); return err }(),
+		1, "unexpected 'x', expected end of line")
+}
+
+func TestSections(t *testing.T) {
+	shape := Section{Name: "shape",
+		Header: func(l input.Line) bool { return Matches(l, Token("0:")) },
+		Parse:  func(para []input.Line) error { return nil }}
+	region := Section{Name: "regions",
+		Header: func(l input.Line) bool { return Matches(l, Token("4x4:")) },
+		Parse: func(para []input.Line) error {
+			_, err := Parse(para[0], func(s *Scanner) (int, error) {
+				if _, err := Token("4x4:")(s); err != nil {
+					return 0, err
+				}
+				return Uint(s)
+			})
+			return err
+		}}
+	isHeader := func(l input.Line) bool { return shape.Header(l) || region.Header(l) }
+	run := func(texts ...string) error {
+		lines := make([]input.Line, len(texts))
+		for i, text := range texts {
+			lines[i] = input.Line{File: "t", Num: i + 1, Text: text}
+		}
+		return Sections(Blocks(lines, isHeader), shape, region)
+	}
+
+	if err := run("0:", "#.", "", "4x4: 1"); err != nil {
+		t.Fatalf("shape then region = %v", err)
+	}
+
+	// a shape after the regions is out of order, the error points at its whole line
+	var pe *input.ParseError
+	err := run("0:", "#.", "4x4: 1", "", "0:")
+	if !errors.As(err, &pe) || pe.Line != 5 || pe.Col != 0 || pe.Msg != "expected a regions section" {
+		t.Errorf("shape after the regions = %v, want 5: expected a regions section", err)
+	}
+	// lines before any header make a block no section takes
+	err = run("#.", "0:")
+	if !errors.As(err, &pe) || pe.Line != 1 || pe.Col != 0 || pe.Msg != "expected a shape section or a regions section" {
+		t.Errorf("lines before a header = %v, want 1: expected a shape section or a regions section", err)
+	}
+
+	// errors from a section's own parser come through untouched
+	err = run("0:", "4x4: x")
+	if !errors.As(err, &pe) || pe.Line != 2 || pe.Col != 6 {
+		t.Errorf("bad region = %v, want it at 2:6", err)
+	}
+}
@@ -0,0 +1,62 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"aoclib/input"
+)
+
+// Section is one kind of block of lines, like a paragraph, told apart by its first line.
+type Section struct {
+	Name   string                      // used in errors, like "shape" or "regions"
+	Header func(first input.Line) bool // whether a paragraph starting with first belongs here
+	Parse  func(para []input.Line) error
+}
+
+// Sections hands every paragraph to the first section whose Header accepts it.
+// Sections come in file order: once a paragraph went to a section, earlier ones are closed.
+func Sections(paragraphs [][]input.Line, sections ...Section) error {
+	cur := 0
+	for _, para := range paragraphs {
+		found := false
+		for i := cur; i < len(sections); i++ {
+			if sections[i].Header(para[0]) {
+				cur, found = i, true
+				break
+			}
+		}
+		if !found {
+			return para[0].Errorf(0, "expected %s", sectionNames(sections[cur:]))
+		}
+		if err := sections[cur].Parse(para); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Blocks splits lines into blocks that each start at a line isHeader accepts, ready for Sections.
+// Blank lines are dropped wherever they are, unlike input.Paragraphs the blocks don't depend on them.
+// Lines before the first header make a block of their own, so Sections can report them.
+func Blocks(lines []input.Line, isHeader func(input.Line) bool) [][]input.Line {
+	var blocks [][]input.Line
+	for _, l := range lines {
+		if strings.TrimSpace(l.Text) == "" {
+			continue
+		}
+		if len(blocks) == 0 || isHeader(l) {
+			blocks = append(blocks, nil)
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], l)
+	}
+	return blocks
+}
+
+func sectionNames(sections []Section) string {
+	names := make([]string, len(sections))
+	for i, s := range sections {
+		names[i] = fmt.Sprintf("a %s section", s.Name)
+	}
+	return orList(names)
+}
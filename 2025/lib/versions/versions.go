@@ -0,0 +1,117 @@
+// Package versions describes the alternative solvers ("versions") a day ships with.
+//
+// Every day keeps a Table next to its main function. The table is what the -v flag
+// picks from, what -list prints, and what gets shown when an unknown version is asked for,
+// so the meaning of each version lives in one place instead of scattered code comments.
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Auto is the -v value that lets a day pick the version from its input.
+const Auto = "auto"
+
+// Policy inspects the input and names the version best suited for it, along with the reason.
+type Policy func(filename string) (name, reason string, err error)
+
+// Method tells how much we can trust the answer of a version.
+type Method int
+
+const (
+	Exact     Method = iota // always gives the right answer
+	Heuristic               // relies on an assumption about the input that may not hold
+	Sampling                // checks only part of the search space, may miss counterexamples
+)
+
+func (m Method) String() string {
+	switch m {
+	case Exact:
+		return "exact"
+	case Heuristic:
+		return "heuristic"
+	case Sampling:
+		return "sampling"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+// Version is a single solver entry of a day.
+type Version struct {
+	Name        string // value accepted by the -v flag, e.g. "1a"
+	Description string // what the approach does, one line
+	Complexity  string // expected time complexity, free form, e.g. "O(n^2)"
+	Method      Method
+	Deprecated  bool // kept for reference only, e.g. known to be too slow for real inputs
+	Run         func(filename string) (string, error)
+}
+
+// Table is the ordered list of versions of a day.
+type Table []Version
+
+// Lookup finds a version by name.
+// The error of an unknown name carries the whole table so the user can pick a valid one.
+func (t Table) Lookup(name string) (Version, error) {
+	for _, v := range t {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unknown version %q, available versions:\n", name)
+	_ = t.Fprint(&sb) // writing to strings.Builder never fails
+	return Version{}, errors.New(strings.TrimRight(sb.String(), "\n"))
+}
+
+// Fprint writes the table in aligned columns, one version per line.
+func (t Table) Fprint(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tMETHOD\tCOMPLEXITY\tSTATUS\tDESCRIPTION")
+	for _, v := range t {
+		status := "ok"
+		if v.Deprecated {
+			status = "deprecated"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Name, v.Method, v.Complexity, status, v.Description)
+	}
+	return tw.Flush()
+}
+
+// Execute runs the version with the given name.
+// Deprecated versions still run, but a warning goes to stderr first.
+func (t Table) Execute(name, filename string) (string, error) {
+	v, err := t.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if v.Deprecated {
+		fmt.Fprintf(os.Stderr, "Warning: version %s is deprecated (%s)\n", v.Name, v.Description)
+	}
+	return v.Run(filename)
+}
+
+// Select resolves Auto into a version name using the day's policy.
+// Any other name is returned as is, so the result can go straight into Execute.
+func (t Table) Select(name, filename string, policy Policy) (string, error) {
+	if name != Auto {
+		return name, nil
+	}
+	if policy == nil {
+		return "", fmt.Errorf("version %q is not supported here, pick one explicitly", Auto)
+	}
+	chosen, reason, err := policy(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to pick a version: %w", err)
+	}
+	if _, err := t.Lookup(chosen); err != nil {
+		return "", err
+	}
+	fmt.Printf("Auto picked version %s: %s\n", chosen, reason)
+	return chosen, nil
+}